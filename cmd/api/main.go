@@ -10,10 +10,14 @@ import (
 	"syscall"
 	"time"
 	"zenith-go/internal/auth"
+	"zenith-go/internal/conference"
 	"zenith-go/internal/config"
+	"zenith-go/internal/export"
 	"zenith-go/internal/handler"
+	"zenith-go/internal/label"
 	"zenith-go/internal/logger"
 	"zenith-go/internal/notification"
+	"zenith-go/internal/printing"
 	"zenith-go/internal/sankhya"
 )
 
@@ -27,11 +31,16 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap permite que http.ResponseController alcance o Flush do writer original (downloads em streaming)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func securityMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Snkjsessionid")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Snkjsessionid, Devicetoken")
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 		w.Header().Set("X-Frame-Options", "DENY")
 		w.Header().Set("X-XSS-Protection", "1; mode=block")
@@ -115,6 +124,76 @@ func startKeepAliveWorker(session *auth.SessionManager, client *sankhya.Client)
 	}()
 }
 
+// WORKER DO RESUMO DIÁRIO DE VALIDADE
+func startExpiryDigestWorker(cfg *config.Config, session *auth.SessionManager, client *sankhya.Client, email *notification.EmailService) {
+	if !cfg.ExpiryDigestEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(1 * time.Minute)
+	go func() {
+		for range ticker.C {
+			now := time.Now()
+			if now.Hour() != cfg.ExpiryDigestHour {
+				continue
+			}
+
+			// Os dois nós rodam o worker; o lock do dia garante um único envio
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			lockKey := "expiry-digest:" + now.Format("2006-01-02")
+			ok, err := session.AcquireLock(ctx, lockKey, 25*time.Hour)
+			if err != nil || !ok {
+				cancel()
+				continue
+			}
+
+			if err := sendExpiryDigest(ctx, cfg, client, email); err != nil {
+				slog.Error("Expiry Digest Worker: Falha ao enviar resumo", "error", err)
+				email.SendError(err, map[string]string{"Context": "Expiry Digest Worker"})
+				// Libera o dia para o próximo tick (de qualquer nó) tentar de novo dentro da mesma hora
+				if err := session.ReleaseLock(context.WithoutCancel(ctx), lockKey); err != nil {
+					slog.Error("Expiry Digest Worker: Falha ao liberar lock", "error", err)
+				}
+			}
+			cancel()
+		}
+	}()
+}
+
+func sendExpiryDigest(ctx context.Context, cfg *config.Config, client *sankhya.Client, email *notification.EmailService) error {
+	armazens := cfg.ExpiryDigestArmazens
+	if len(armazens) == 0 {
+		var err error
+		if armazens, err = client.ListWarehouseCodes(ctx); err != nil {
+			return err
+		}
+	}
+
+	report, err := client.GetExpiryReport(ctx, armazens, sankhya.ExpiryReportFilter{Dias: cfg.ExpiryDigestDays})
+	if err != nil {
+		return err
+	}
+	if len(report.Itens) == 0 {
+		slog.Info("Expiry Digest Worker: Nenhum endereço vencido ou a vencer", "dias", report.Dias)
+		return nil
+	}
+
+	items := make([]notification.ExpiryDigestItem, 0, len(report.Itens))
+	for _, it := range report.Itens {
+		items = append(items, notification.ExpiryDigestItem{
+			Armazem:       fmt.Sprintf("%d - %s", it.CodArm, it.DesArm),
+			Endereco:      fmt.Sprintf("%d (%s-%d-%s)", it.SeqEnd, it.CodRua, it.CodPrd, it.CodApt),
+			Produto:       fmt.Sprintf("%d - %s", it.CodProd, it.DescrProd),
+			Marca:         it.Marca,
+			Validade:      it.DatVal,
+			DiasRestantes: it.DiasRestantes,
+			Quantidade:    export.FormatDecimal(it.QtdPro) + " " + it.CodVol,
+		})
+	}
+
+	return email.SendExpiryDigest(cfg.ExpiryDigestRecipients, report.Dias, items)
+}
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -137,6 +216,13 @@ func main() {
 	}
 	slog.Info("Autenticação do sistema realizada com sucesso!")
 
+	slog.Info("Verificando mapeamento de ações do ERP...")
+	if err := sankhyaClient.CheckErpActions(ctxBg); err != nil {
+		slog.Error("Mapeamento de ações inválido para este ambiente", "error", err)
+		emailService.SendError(err, map[string]string{"Context": "Startup ERP Actions Check"})
+		panic(err)
+	}
+
 	slog.Info("Conectando ao Redis...", "addr", cfg.RedisAddr)
 	sessionManager, err := auth.NewSessionManager(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB, 50)
 	if err != nil {
@@ -151,6 +237,17 @@ func main() {
 	startKeepAliveWorker(sessionManager, sankhyaClient)
 	// -------------------------------------
 
+	if cfg.ExpiryDigestEnabled {
+		slog.Info("Iniciando Worker do Resumo de Validade...", "hora", cfg.ExpiryDigestHour, "dias", cfg.ExpiryDigestDays)
+		startExpiryDigestWorker(cfg, sessionManager, sankhyaClient, emailService)
+	}
+
+	printQueue := printing.NewQueue(sessionManager.Redis(), cfg.PrintMaxTentativas, time.Duration(cfg.PrintTimeoutSegundos)*time.Second)
+	if len(cfg.Printers) > 0 {
+		slog.Info("Iniciando Workers da Fila de Impressão...", "impressoras", len(cfg.Printers))
+		printQueue.Start(ctxBg, 2)
+	}
+
 	authHandler := &handler.AuthHandler{
 		Client:   sankhyaClient,
 		Config:   cfg,
@@ -178,11 +275,34 @@ func main() {
 		Notifier: emailService,
 	}
 
+	// Streams SSE ficam abertos indefinidamente: são encerrados no RegisterOnShutdown
+	streamsCtx, cancelStreams := context.WithCancel(context.Background())
+	defer cancelStreams()
+
 	romaneioHandler := &handler.RomaneioHandler{
 		Client:   sankhyaClient,
 		Config:   cfg,
 		Session:  sessionManager,
 		Notifier: emailService,
+		Locks:    conference.NewLocks(sessionManager.Redis(), time.Duration(cfg.ConfLockMinutos)*time.Minute),
+		Events:   conference.NewBus(sessionManager.Redis()),
+		Streams:  streamsCtx,
+	}
+
+	exportHandler := &handler.ExportHandler{
+		Client:   sankhyaClient,
+		Config:   cfg,
+		Session:  sessionManager,
+		Notifier: emailService,
+	}
+
+	labelHandler := &handler.LabelHandler{
+		Client:    sankhyaClient,
+		Config:    cfg,
+		Session:   sessionManager,
+		Notifier:  emailService,
+		Templates: label.NewTemplates(cfg.LabelTemplatesDir),
+		Queue:     printQueue,
 	}
 
 	mux := http.NewServeMux()
@@ -192,15 +312,47 @@ func main() {
 	mux.HandleFunc("/apiv1/permissions", authHandler.HandleGetPermissions)
 	mux.HandleFunc("/apiv1/search-items", productHandler.HandleSearchItems)
 	mux.HandleFunc("/apiv1/get-item-details", productHandler.HandleGetItemDetails)
+	mux.HandleFunc("/apiv1/get-item-details-bulk", productHandler.HandleGetItemDetailsBulk)
 	mux.HandleFunc("/apiv1/get-picking-locations", productHandler.HandleGetPickingLocations)
 	mux.HandleFunc("/apiv1/get-history", productHandler.HandleGetHistory)
+	mux.HandleFunc("/apiv1/history", productHandler.HandleSearchHistory)
+	mux.HandleFunc("/apiv1/history-detail", productHandler.HandleGetOperationDetail)
+	mux.HandleFunc("/apiv1/resolve-barcode", productHandler.HandleResolveBarcode)
+	mux.HandleFunc("/apiv1/product-stock", productHandler.HandleGetProductStock)
+	mux.HandleFunc("/apiv1/expiry-report", productHandler.HandleGetExpiryReport)
+	mux.HandleFunc("/apiv1/replenishment", productHandler.HandleGetReplenishment)
+	mux.HandleFunc("/apiv1/empty-addresses", productHandler.HandleListEmptyAddresses)
+	mux.HandleFunc("/apiv1/putaway", productHandler.HandleGetPutawaySuggestions)
+	mux.HandleFunc("/apiv1/occupancy", productHandler.HandleGetOccupancyMap)
+	mux.HandleFunc("/apiv1/label", labelHandler.HandleLabelZPL)
+	mux.HandleFunc("/apiv1/label/preview", labelHandler.HandleLabelPreview)
+	mux.HandleFunc("/apiv1/label/print", labelHandler.HandleLabelPrint)
+	mux.HandleFunc("/apiv1/label/job", labelHandler.HandleLabelJobStatus)
+	mux.HandleFunc("/apiv1/printers", labelHandler.HandleListPrinters)
 	mux.HandleFunc("/apiv1/execute-transaction", transactionHandler.HandleExecuteTransaction)
+	mux.HandleFunc("/apiv1/correcoes-pendentes", transactionHandler.HandleListCorrecoesPendentes)
+	mux.HandleFunc("/apiv1/aprovar-correcao", transactionHandler.HandleAprovarCorrecao)
+	mux.HandleFunc("/apiv1/rejeitar-correcao", transactionHandler.HandleRejeitarCorrecao)
+	mux.HandleFunc("/apiv1/export/history", exportHandler.HandleExportHistory)
+	mux.HandleFunc("/apiv1/export/stock", exportHandler.HandleExportStock)
+	mux.HandleFunc("/apiv1/export/romaneio", exportHandler.HandleExportRomaneio)
+	mux.HandleFunc("/apiv1/export/conferencia", exportHandler.HandleConferenceReport)
 	mux.HandleFunc("/apiv1/health", healthHandler.HandleHealthCheck)
 	mux.HandleFunc("/apiv1/romaneio", romaneioHandler.HandleGetRomaneios)
+	mux.HandleFunc("/apiv1/romaneio-busca", romaneioHandler.HandleSearchRomaneios)
 	mux.HandleFunc("/apiv1/romaneio-detalhe", romaneioHandler.HandleGetRomaneioDetalhes)
 	mux.HandleFunc("/apiv1/iniciar-conferencia", romaneioHandler.HandleIniciarConferencia)
 	mux.HandleFunc("/apiv1/conferir-item", romaneioHandler.HandleConferirItem)
+	mux.HandleFunc("/apiv1/conferir-codigo", romaneioHandler.HandleConferirPorCodigo)
+	mux.HandleFunc("/apiv1/conferencia-progresso", romaneioHandler.HandleGetConferenceProgress)
+	mux.HandleFunc("/apiv1/conferencia-item/ajustar", romaneioHandler.HandleAjustarItemConferencia)
+	mux.HandleFunc("/apiv1/conferencia-item/historico", romaneioHandler.HandleConferenceItemHistory)
 	mux.HandleFunc("/apiv1/finalizar-conferencia", romaneioHandler.HandleFinalizarConferencia)
+	mux.HandleFunc("/apiv1/conferencia-lock", romaneioHandler.HandleGetConferenceLock)
+	mux.HandleFunc("/apiv1/conferencia-lock/transferir", romaneioHandler.HandleTransferConferenceLock)
+	mux.HandleFunc("/apiv1/conferencia-lock/liberar", romaneioHandler.HandleReleaseConferenceLock)
+	mux.HandleFunc("/apiv1/conferencia-eventos", romaneioHandler.HandleConferenceEvents)
+	mux.HandleFunc("/apiv1/conferencia-eventos/ticket", romaneioHandler.HandleConferenceEventsTicket)
 	
 	// ROTA DE TESTE DE EMAIL
 	mux.HandleFunc("/apiv1/test-email", healthHandler.HandleTestEmail)
@@ -211,6 +363,7 @@ func main() {
 		Addr:    ":8080",
 		Handler: finalHandler,
 	}
+	srv.RegisterOnShutdown(cancelStreams)
 
 	stopChan := make(chan os.Signal, 1)
 	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
@@ -0,0 +1,102 @@
+// printer-stub simula uma impressora Zebra na rede: escuta raw TCP (como a porta 9100),
+// registra cada job recebido e, opcionalmente, salva o ZPL e a prévia PNG em disco.
+//
+// Uso: go run ./cmd/printer-stub -addr :9100 -out ./etiquetas
+// No cadastro de impressoras, aponte "endereco" para o host:porta do stub.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"zenith-go/internal/label"
+)
+
+func main() {
+	addr := flag.String("addr", ":9100", "endereço de escuta (host:porta)")
+	out := flag.String("out", "", "diretório para salvar o ZPL e a prévia PNG de cada job (vazio = só log)")
+	offlineEvery := flag.Int("offline-every", 0, "fica offline após cada N jobs, para testar as novas tentativas (0 = nunca)")
+	offlineFor := flag.Duration("offline-for", 15*time.Second, "tempo offline (porta fechada) quando -offline-every dispara")
+	flag.Parse()
+
+	if *out != "" {
+		if err := os.MkdirAll(*out, 0o755); err != nil {
+			slog.Error("Não foi possível criar o diretório de saída", "dir", *out, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	var count int64
+	for {
+		ln, err := net.Listen("tcp", *addr)
+		if err != nil {
+			slog.Error("Falha ao abrir a porta", "addr", *addr, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Impressora simulada aguardando jobs", "addr", ln.Addr().String())
+
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				slog.Error("Falha ao aceitar conexão", "error", err)
+				continue
+			}
+			count++
+			go handle(conn, count, *out)
+
+			if *offlineEvery > 0 && count%int64(*offlineEvery) == 0 {
+				break
+			}
+		}
+
+		// Porta fechada: o envio seguinte recebe "connection refused", como uma impressora desligada
+		ln.Close()
+		slog.Warn("Impressora simulada offline", "por", offlineFor.String())
+		time.Sleep(*offlineFor)
+	}
+}
+
+func handle(conn net.Conn, n int64, out string) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		slog.Error("Falha ao ler job", "job", n, "error", err)
+		return
+	}
+	zpl := string(data)
+	etiquetas := strings.Count(zpl, "^XA")
+	slog.Info("Job recebido", "job", n, "origem", conn.RemoteAddr().String(), "bytes", len(data), "etiquetas", etiquetas)
+
+	if out == "" {
+		return
+	}
+
+	base := filepath.Join(out, fmt.Sprintf("job_%s_%03d", time.Now().Format("20060102_150405"), n))
+	if err := os.WriteFile(base+".zpl", data, 0o644); err != nil {
+		slog.Error("Falha ao salvar ZPL", "error", err)
+		return
+	}
+
+	img, err := label.Preview(zpl)
+	if err != nil {
+		slog.Warn("Prévia não gerada", "job", n, "error", err)
+		return
+	}
+	f, err := os.Create(base + ".png")
+	if err != nil {
+		slog.Error("Falha ao salvar prévia", "error", err)
+		return
+	}
+	defer f.Close()
+	png.Encode(f, img)
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AcquireLock tenta obter um lock distribuído (SET NX). Com dois nós atrás do NGINX,
+// garante que jobs agendados rodem em apenas um deles. O lock expira sozinho no ttl.
+func (sm *SessionManager) AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := sm.client.SetNX(ctx, "locks:"+key, time.Now().Format(time.RFC3339), ttl).Result()
+	if err != nil {
+		return false, ErrRedisConnection
+	}
+	return ok, nil
+}
+
+// ReleaseLock libera antes do ttl um lock obtido com AcquireLock
+func (sm *SessionManager) ReleaseLock(ctx context.Context, key string) error {
+	if err := sm.client.Del(ctx, "locks:"+key).Err(); err != nil {
+		return ErrRedisConnection
+	}
+	return nil
+}
+
+// Redis expõe o cliente para serviços que guardam estado compartilhado entre os nós (ex.: fila de impressão)
+func (sm *SessionManager) Redis() *redis.Client {
+	return sm.client
+}
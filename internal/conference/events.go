@@ -0,0 +1,145 @@
+package conference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tipos de evento publicados para os coletores e o painel
+const (
+	EventoConferenciaIniciada   = "conferencia-iniciada"
+	EventoItemConferido         = "item-conferido"
+	EventoItemEstornado         = "item-estornado" // Linha voltou para não conferida
+	EventoItemAjustado          = "item-ajustado"  // Quantidade embarcada corrigida
+	EventoConferenciaFinalizada = "conferencia-finalizada"
+	EventoPosseAlterada         = "posse-alterada" // Supervisor transferiu ou liberou a conferência
+)
+
+// ErrEventosIndisponivel indica falha no pub/sub do Redis (a conferência em si não é afetada)
+var ErrEventosIndisponivel = errors.New("eventos de conferência indisponíveis")
+
+const eventsChannel = "conf:events:"
+
+// Event é uma mudança na conferência de um romaneio, entregue a todos os inscritos
+type Event struct {
+	Tipo     string `json:"tipo"`
+	NuUnico  int    `json:"nuUnico"`
+	CodUsu   int    `json:"codUsu"`
+	Username string `json:"username"`
+	Momento  string `json:"momento"`
+	Dados    any    `json:"dados,omitempty"`
+}
+
+// ItemConferido são os dados do evento item-conferido
+type ItemConferido struct {
+	NumReg       int     `json:"num_reg"`
+	CodProd      int     `json:"codigo_produto,omitempty"` // Só na conferência por código
+	Descricao    string  `json:"descricao,omitempty"`
+	Unidade      string  `json:"unidade,omitempty"`
+	QtdEmbarcada float64 `json:"qtd_embarcada"`
+}
+
+// ItemAjustado são os dados dos eventos item-estornado e item-ajustado
+type ItemAjustado struct {
+	NumReg       int     `json:"num_reg"`
+	CodProd      int     `json:"codigo_produto"`
+	Unidade      string  `json:"unidade"`
+	QtdAnterior  float64 `json:"qtd_anterior"`
+	QtdEmbarcada float64 `json:"qtd_embarcada"` // 0 no estorno
+	Motivo       string  `json:"motivo"`
+}
+
+// Bus distribui os eventos pelo pub/sub do Redis, para que os inscritos em qualquer um
+// dos nós recebam o que foi conferido no outro. Não há histórico: quem conecta depois
+// consulta o andamento e passa a receber dali em diante.
+type Bus struct {
+	rdb *redis.Client
+}
+
+func NewBus(rdb *redis.Client) *Bus {
+	return &Bus{rdb: rdb}
+}
+
+// Publish envia o evento para o canal do romaneio
+func (b *Bus) Publish(ctx context.Context, e Event) error {
+	if e.Momento == "" {
+		e.Momento = time.Now().Format(time.RFC3339)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := b.rdb.Publish(ctx, eventsChannel+strconv.Itoa(e.NuUnico), data).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrEventosIndisponivel, err)
+	}
+	return nil
+}
+
+// Notify publica sem bloquear a resposta da operação: a conferência já foi gravada no ERP,
+// então uma falha aqui só é registrada
+func (b *Bus) Notify(e Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := b.Publish(ctx, e); err != nil {
+		slog.Warn("Falha ao publicar evento da conferência", "tipo", e.Tipo, "nuUnico", e.NuUnico, "error", err)
+	}
+}
+
+// Subscription recebe os eventos de um romaneio (ou de todos)
+type Subscription struct {
+	ps     *redis.PubSub
+	events chan Event
+	done   chan struct{}
+}
+
+// Subscribe inscreve no romaneio; nuUnico 0 recebe os eventos de todos os romaneios (painel).
+// Retorna erro se o Redis não confirmar a inscrição.
+func (b *Bus) Subscribe(ctx context.Context, nuUnico int) (*Subscription, error) {
+	var ps *redis.PubSub
+	if nuUnico == 0 {
+		ps = b.rdb.PSubscribe(ctx, eventsChannel+"*")
+	} else {
+		ps = b.rdb.Subscribe(ctx, eventsChannel+strconv.Itoa(nuUnico))
+	}
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return nil, fmt.Errorf("%w: %v", ErrEventosIndisponivel, err)
+	}
+
+	s := &Subscription{ps: ps, events: make(chan Event, 16), done: make(chan struct{})}
+	go s.decode()
+	return s, nil
+}
+
+func (s *Subscription) decode() {
+	defer close(s.events)
+	for msg := range s.ps.Channel() {
+		var e Event
+		if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+			slog.Warn("Evento de conferência inválido descartado", "canal", msg.Channel, "error", err)
+			continue
+		}
+		select {
+		case s.events <- e:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Events é fechado quando a inscrição termina
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Close() error {
+	close(s.done)
+	return s.ps.Close()
+}
@@ -0,0 +1,166 @@
+// Package conference guarda no Redis o estado compartilhado da conferência de romaneios
+// (exclusividade por NUUNICO e eventos em tempo real), para valer nos dois nós atrás do NGINX.
+package conference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrLockOutroOperador = errors.New("conferência em andamento com outro operador")
+	ErrLockIndisponivel  = errors.New("controle de conferência indisponível")
+)
+
+const lockKey = "conf:lock:"
+
+// Holder identifica quem confere: o usuário e o coletor
+type Holder struct {
+	CodUsu   int    `json:"codUsu"`
+	Username string `json:"username"`
+	Device   string `json:"device"` // Vazio = transferido, o próximo coletor do usuário assume
+}
+
+// Lease é a posse da conferência de um romaneio. Expira sem atividade.
+type Lease struct {
+	NuUnico    int    `json:"nuUnico"`
+	Holder            // Dono atual
+	Desde      string `json:"desde"`
+	RenovadoEm string `json:"renovadoEm"`
+	ExpiraEm   string `json:"expiraEm"`
+}
+
+// LockError informa quem está com a conferência
+type LockError struct {
+	Lease *Lease
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("%s (%s, usuário %d)", ErrLockOutroOperador.Error(), e.Lease.Username, e.Lease.CodUsu)
+}
+func (e *LockError) Unwrap() error { return ErrLockOutroOperador }
+
+type Locks struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func NewLocks(rdb *redis.Client, ttl time.Duration) *Locks {
+	return &Locks{rdb: rdb, ttl: ttl}
+}
+
+// acquireScript cria ou renova a posse de forma atômica. Retorna {0, atual} quando outro
+// operador (ou o mesmo usuário em outro coletor) está com a conferência.
+var acquireScript = redis.NewScript(`
+local atual = redis.call('GET', KEYS[1])
+if atual then
+	local l = cjson.decode(atual)
+	if tonumber(l.codUsu) ~= tonumber(ARGV[1]) or (l.device ~= '' and l.device ~= ARGV[2]) then
+		return {0, atual}
+	end
+	redis.call('SET', KEYS[1], ARGV[4], 'PX', ARGV[5])
+	return {1, ARGV[4]}
+end
+redis.call('SET', KEYS[1], ARGV[3], 'PX', ARGV[5])
+return {2, ARGV[3]}
+`)
+
+// Acquire pega a conferência para o holder ou renova a posse existente dele.
+// created indica que a posse foi criada agora (não existia).
+func (l *Locks) Acquire(ctx context.Context, nuUnico int, h Holder) (lease *Lease, created bool, err error) {
+	key := lockKey + strconv.Itoa(nuUnico)
+	now := time.Now()
+
+	current, err := l.Get(ctx, nuUnico)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Renovação preserva "desde" e adota o coletor quando a posse veio de uma transferência
+	renewed := Lease{NuUnico: nuUnico, Holder: h, Desde: now.Format(time.RFC3339)}
+	if current != nil {
+		renewed.Desde = current.Desde
+		if renewed.Username == "" {
+			renewed.Username = current.Username
+		}
+	}
+	fresh := Lease{NuUnico: nuUnico, Holder: h, Desde: now.Format(time.RFC3339)}
+
+	freshJSON, err := l.encode(&fresh, now)
+	if err != nil {
+		return nil, false, err
+	}
+	renewedJSON, err := l.encode(&renewed, now)
+	if err != nil {
+		return nil, false, err
+	}
+
+	res, err := acquireScript.Run(ctx, l.rdb, []string{key}, h.CodUsu, h.Device, freshJSON, renewedJSON, l.ttl.Milliseconds()).Slice()
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrLockIndisponivel, err)
+	}
+
+	status, _ := res[0].(int64)
+	payload, _ := res[1].(string)
+	var result Lease
+	if err := json.Unmarshal([]byte(payload), &result); err != nil {
+		return nil, false, err
+	}
+	if status == 0 {
+		return nil, false, &LockError{Lease: &result}
+	}
+	return &result, status == 2, nil
+}
+
+// Get retorna a posse atual (nil quando a conferência está livre)
+func (l *Locks) Get(ctx context.Context, nuUnico int) (*Lease, error) {
+	data, err := l.rdb.Get(ctx, lockKey+strconv.Itoa(nuUnico)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLockIndisponivel, err)
+	}
+
+	var lease Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return nil, err
+	}
+	return &lease, nil
+}
+
+// Release libera a conferência (finalização ou supervisor)
+func (l *Locks) Release(ctx context.Context, nuUnico int) error {
+	if err := l.rdb.Del(ctx, lockKey+strconv.Itoa(nuUnico)).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrLockIndisponivel, err)
+	}
+	return nil
+}
+
+// Transfer passa a conferência para outro usuário (supervisor). Sem device, o próximo
+// coletor do novo dono que mexer na conferência assume a posse.
+func (l *Locks) Transfer(ctx context.Context, nuUnico int, h Holder) (*Lease, error) {
+	now := time.Now()
+	lease := Lease{NuUnico: nuUnico, Holder: h, Desde: now.Format(time.RFC3339)}
+	data, err := l.encode(&lease, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.rdb.Set(ctx, lockKey+strconv.Itoa(nuUnico), data, l.ttl).Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLockIndisponivel, err)
+	}
+	return &lease, nil
+}
+
+func (l *Locks) encode(lease *Lease, now time.Time) (string, error) {
+	lease.RenovadoEm = now.Format(time.RFC3339)
+	lease.ExpiraEm = now.Add(l.ttl).Format(time.RFC3339)
+	data, err := json.Marshal(lease)
+	return string(data), err
+}
@@ -0,0 +1,47 @@
+package conference
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrTicketInvalido indica ticket de stream inexistente, vencido ou já usado
+var ErrTicketInvalido = errors.New("ticket de stream inválido ou expirado")
+
+const ticketKey = "conf:ticket:"
+
+// TicketTTL é a validade do ticket: só o tempo de abrir o EventSource
+const TicketTTL = 30 * time.Second
+
+// IssueTicket troca o JWT por um ticket curto e de uso único para abrir o stream SSE.
+// EventSource não envia headers, e o ticket na URL não expõe o JWT nos logs de acesso.
+func (b *Bus) IssueTicket(ctx context.Context, bearerToken string) (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	ticket := hex.EncodeToString(raw)
+
+	if err := b.rdb.Set(ctx, ticketKey+ticket, bearerToken, TicketTTL).Err(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrEventosIndisponivel, err)
+	}
+	return ticket, nil
+}
+
+// RedeemTicket consome o ticket (GETDEL) e devolve o JWT que o emitiu
+func (b *Bus) RedeemTicket(ctx context.Context, ticket string) (string, error) {
+	token, err := b.rdb.GetDel(ctx, ticketKey+ticket).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrTicketInvalido
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrEventosIndisponivel, err)
+	}
+	return token, nil
+}
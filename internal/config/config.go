@@ -42,6 +42,36 @@ type Config struct {
 	SMTPPort        int
 	SMTPUser        string
 	SMTPPass        string
+
+	// Correção de Estoque (Aprovação)
+	CorrecaoLimiteAbs float64
+	CorrecaoLimitePct float64
+
+	// Mapeamento de ações do ERP (por ambiente)
+	SankhyaEnv string
+	ErpActions ErpActions
+
+	// Resumo diário de validade por e-mail
+	ExpiryDigestEnabled    bool
+	ExpiryDigestHour       int
+	ExpiryDigestDays       int
+	ExpiryDigestRecipients []string
+	ExpiryDigestArmazens   []int // Vazio = todos os armazéns
+
+	// Reposição de picking (usados quando o endereço não está em AD_PICKMIN)
+	ReposicaoMinimoPadrao float64
+	ReposicaoAlvoPadrao   float64
+
+	// Etiquetas ZPL: diretório com templates (<dir>/<tipo>.zpl e <dir>/<codArm>/<tipo>.zpl)
+	LabelTemplatesDir string
+
+	// Impressão em rede (raw TCP): cadastro por armazém e política de reenvio
+	Printers             Printers
+	PrintMaxTentativas   int
+	PrintTimeoutSegundos int
+
+	// Conferência de romaneio: posse exclusiva expira após N minutos sem atividade
+	ConfLockMinutos int
 }
 
 func Load() (*Config, error) {
@@ -64,6 +94,10 @@ func Load() (*Config, error) {
 	emailEnabled, _ := strconv.ParseBool(os.Getenv("EMAIL_NOTIFICATIONS_ENABLED"))
 	smtpPort, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
 	
+	// Limites para correção direta (0 = sem limite)
+	corrLimiteAbs, _ := strconv.ParseFloat(os.Getenv("CORRECAO_LIMITE_ABSOLUTO"), 64)
+	corrLimitePct, _ := strconv.ParseFloat(os.Getenv("CORRECAO_LIMITE_PERCENTUAL"), 64)
+
 	recipientsStr := os.Getenv("EMAIL_RECIPIENTS")
 	var recipients []string
 	if recipientsStr != "" {
@@ -73,6 +107,53 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// Resumo de validade: sem destinatários próprios, usa os mesmos dos alertas
+	expiryEnabled, _ := strconv.ParseBool(os.Getenv("EXPIRY_DIGEST_ENABLED"))
+	expiryHour, err := strconv.Atoi(os.Getenv("EXPIRY_DIGEST_HOUR"))
+	if err != nil || expiryHour < 0 || expiryHour > 23 {
+		expiryHour = 7
+	}
+	expiryDays, _ := strconv.Atoi(os.Getenv("EXPIRY_DIGEST_DAYS"))
+	if expiryDays <= 0 {
+		expiryDays = 30
+	}
+	expiryRecipients := recipients
+	if v := os.Getenv("EXPIRY_DIGEST_RECIPIENTS"); v != "" {
+		expiryRecipients = nil
+		for _, p := range strings.Split(v, ",") {
+			expiryRecipients = append(expiryRecipients, strings.TrimSpace(p))
+		}
+	}
+	var expiryArmazens []int
+	for _, p := range strings.Split(os.Getenv("EXPIRY_DIGEST_ARMAZENS"), ",") {
+		if codArm, err := strconv.Atoi(strings.TrimSpace(p)); err == nil {
+			expiryArmazens = append(expiryArmazens, codArm)
+		}
+	}
+
+	// Mínimo/alvo padrão para reposição de picking (0 = só endereços cadastrados em AD_PICKMIN)
+	reposMinimo, _ := strconv.ParseFloat(os.Getenv("REPOSICAO_MINIMO_PADRAO"), 64)
+	reposAlvo, _ := strconv.ParseFloat(os.Getenv("REPOSICAO_ALVO_PADRAO"), 64)
+
+	labelDir := os.Getenv("LABEL_TEMPLATES_DIR")
+	if labelDir == "" {
+		labelDir = "config/labels"
+	}
+
+	printMaxTentativas, _ := strconv.Atoi(os.Getenv("PRINT_MAX_TENTATIVAS"))
+	if printMaxTentativas <= 0 {
+		printMaxTentativas = 3
+	}
+	printTimeout, _ := strconv.Atoi(os.Getenv("PRINT_TIMEOUT_SEGUNDOS"))
+	if printTimeout <= 0 {
+		printTimeout = 5
+	}
+
+	confLockMinutos, _ := strconv.Atoi(os.Getenv("CONF_LOCK_MINUTOS"))
+	if confLockMinutos <= 0 {
+		confLockMinutos = 15
+	}
+
 	cfg := &Config{
 		ApiUrl:               os.Getenv("SANKHYA_API_URL"),
 		TransactionUrl:       os.Getenv("SANKHYA_TRANSACTION_URL"),
@@ -96,7 +177,33 @@ func Load() (*Config, error) {
 		SMTPPort:        smtpPort,
 		SMTPUser:        os.Getenv("SMTP_USER"),
 		SMTPPass:        os.Getenv("SMTP_PASS"),
+		CorrecaoLimiteAbs: corrLimiteAbs,
+		CorrecaoLimitePct: corrLimitePct,
+		ExpiryDigestEnabled:    expiryEnabled,
+		ExpiryDigestHour:       expiryHour,
+		ExpiryDigestDays:       expiryDays,
+		ExpiryDigestRecipients: expiryRecipients,
+		ExpiryDigestArmazens:   expiryArmazens,
+		ReposicaoMinimoPadrao:  reposMinimo,
+		ReposicaoAlvoPadrao:    reposAlvo,
+		LabelTemplatesDir:      labelDir,
+		PrintMaxTentativas:     printMaxTentativas,
+		PrintTimeoutSegundos:   printTimeout,
+		ConfLockMinutos:        confLockMinutos,
+	}
+
+	cfg.SankhyaEnv = os.Getenv("SANKHYA_ENV")
+	actions, err := loadErpActions(os.Getenv("ERP_ACTIONS_FILE"), cfg.SankhyaEnv)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ErpActions = actions
+
+	printers, err := loadPrinters(os.Getenv("PRINTERS_FILE"))
+	if err != nil {
+		return nil, err
 	}
+	cfg.Printers = printers
 
 	if cfg.ApiUrl == "" || cfg.TransactionUrl == "" || cfg.JwtSecret == "" || cfg.SankhyaRenewUrl == "" {
 		return nil, fmt.Errorf("variáveis de ambiente obrigatórias não preenchidas (verifique SANKHYA_RENEW_URL)")
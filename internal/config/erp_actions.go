@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErpAction identifica um botão de ação (e a procedure, quando for STP) no Sankhya
+type ErpAction struct {
+	ActionID string `json:"actionId"`
+	ProcName string `json:"procName,omitempty"`
+}
+
+// ErpActions reúne todas as ações do ERP disparadas pela API
+type ErpActions struct {
+	Correcao      ErpAction `json:"correcao"`
+	Movimentacao  ErpAction `json:"movimentacao"`
+	IniciarConf   ErpAction `json:"iniciarConferencia"`
+	ConferirItem  ErpAction `json:"conferirItem"`
+	FinalizarConf ErpAction `json:"finalizarConferencia"`
+}
+
+// erpActionsFile é o formato do arquivo de mapeamento: valores padrão + sobrescritas por ambiente
+type erpActionsFile struct {
+	Default      ErpActions            `json:"default"`
+	Environments map[string]ErpActions `json:"environments"`
+}
+
+const defaultErpActionsFile = "config/erp_actions.json"
+
+var (
+	actionIDRegex = regexp.MustCompile(`^\d+$`)
+	procNameRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_$#]*$`)
+)
+
+// loadErpActions lê o arquivo de mapeamento e aplica as sobrescritas do ambiente informado.
+// O arquivo é obrigatório: sem ele a API não sobe, para não disparar IDs de outro ambiente.
+func loadErpActions(path string, env string) (ErpActions, error) {
+	if path == "" {
+		path = defaultErpActionsFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ErpActions{}, fmt.Errorf("erro ao ler mapeamento de ações (%s): %w", path, err)
+	}
+
+	var file erpActionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return ErpActions{}, fmt.Errorf("mapeamento de ações inválido (%s): %w", path, err)
+	}
+
+	actions := file.Default
+	if env != "" {
+		override, ok := file.Environments[env]
+		if !ok {
+			return ErpActions{}, fmt.Errorf("ambiente '%s' não existe no mapeamento de ações (%s)", env, path)
+		}
+		actions = actions.merge(override)
+	}
+
+	if err := actions.Validate(); err != nil {
+		return ErpActions{}, fmt.Errorf("mapeamento de ações inválido (%s): %w", path, err)
+	}
+	return actions, nil
+}
+
+// merge sobrescreve apenas os campos preenchidos em override
+func (a ErpActions) merge(override ErpActions) ErpActions {
+	pick := func(base, over ErpAction) ErpAction {
+		if over.ActionID != "" {
+			base.ActionID = over.ActionID
+		}
+		if over.ProcName != "" {
+			base.ProcName = over.ProcName
+		}
+		return base
+	}
+
+	return ErpActions{
+		Correcao:      pick(a.Correcao, override.Correcao),
+		Movimentacao:  pick(a.Movimentacao, override.Movimentacao),
+		IniciarConf:   pick(a.IniciarConf, override.IniciarConf),
+		ConferirItem:  pick(a.ConferirItem, override.ConferirItem),
+		FinalizarConf: pick(a.FinalizarConf, override.FinalizarConf),
+	}
+}
+
+// Validate confere se todas as ações têm ID numérico e se as STPs têm procedure
+func (a ErpActions) Validate() error {
+	var problems []string
+
+	check := func(name string, action ErpAction, needsProc bool) {
+		if !actionIDRegex.MatchString(action.ActionID) {
+			problems = append(problems, fmt.Sprintf("%s: actionId '%s' deve ser numérico", name, action.ActionID))
+		}
+		if needsProc && !procNameRegex.MatchString(action.ProcName) {
+			problems = append(problems, fmt.Sprintf("%s: procName '%s' inválido", name, action.ProcName))
+		}
+	}
+
+	check("correcao", a.Correcao, false)
+	check("movimentacao", a.Movimentacao, true)
+	check("iniciarConferencia", a.IniciarConf, true)
+	check("conferirItem", a.ConferirItem, true)
+	check("finalizarConferencia", a.FinalizarConf, true)
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// All lista as ações com um nome legível (usado na verificação de startup)
+func (a ErpActions) All() map[string]ErpAction {
+	return map[string]ErpAction{
+		"correcao":             a.Correcao,
+		"movimentacao":         a.Movimentacao,
+		"iniciarConferencia":   a.IniciarConf,
+		"conferirItem":         a.ConferirItem,
+		"finalizarConferencia": a.FinalizarConf,
+	}
+}
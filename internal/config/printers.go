@@ -0,0 +1,142 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+)
+
+// Printer é uma impressora Zebra da rede do armazém (raw TCP, normalmente porta 9100)
+type Printer struct {
+	ID       string `json:"id"`
+	CodArm   int    `json:"codArm"`
+	Nome     string `json:"nome"`
+	Endereco string `json:"endereco"` // host:porta (sem porta = 9100)
+	Padrao   bool   `json:"padrao"`   // Usada quando o pedido não informa a impressora
+}
+
+// Printers é o cadastro de impressoras carregado de config/printers.json
+type Printers []Printer
+
+type printersFile struct {
+	Printers Printers `json:"printers"`
+}
+
+const (
+	defaultPrintersFile = "config/printers.json"
+	defaultPrinterPort  = "9100"
+)
+
+// Find busca a impressora pelo ID
+func (p Printers) Find(id string) (Printer, bool) {
+	for _, printer := range p {
+		if printer.ID == id {
+			return printer, true
+		}
+	}
+	return Printer{}, false
+}
+
+// ForWarehouse lista as impressoras de um armazém
+func (p Printers) ForWarehouse(codArm int) Printers {
+	result := Printers{}
+	for _, printer := range p {
+		if printer.CodArm == codArm {
+			result = append(result, printer)
+		}
+	}
+	return result
+}
+
+// Default retorna a impressora padrão do armazém (ou a única cadastrada nele)
+func (p Printers) Default(codArm int) (Printer, bool) {
+	doArmazem := p.ForWarehouse(codArm)
+	for _, printer := range doArmazem {
+		if printer.Padrao {
+			return printer, true
+		}
+	}
+	if len(doArmazem) == 1 {
+		return doArmazem[0], true
+	}
+	return Printer{}, false
+}
+
+// loadPrinters lê o cadastro de impressoras. Sem PRINTERS_FILE e sem o arquivo padrão,
+// a impressão em rede fica desabilitada (cadastro vazio).
+func loadPrinters(path string) (Printers, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultPrintersFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			slog.Warn("Cadastro de impressoras não encontrado, impressão em rede desabilitada", "file", path)
+			return Printers{}, nil
+		}
+		return nil, fmt.Errorf("erro ao ler cadastro de impressoras (%s): %w", path, err)
+	}
+
+	var file printersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("cadastro de impressoras inválido (%s): %w", path, err)
+	}
+
+	if err := file.Printers.normalize(); err != nil {
+		return nil, fmt.Errorf("cadastro de impressoras inválido (%s): %w", path, err)
+	}
+	return file.Printers, nil
+}
+
+// normalize completa a porta padrão e valida IDs, armazéns e endereços
+func (p Printers) normalize() error {
+	var problems []string
+	ids := make(map[string]bool)
+	padroes := make(map[int]int)
+
+	for i := range p {
+		printer := &p[i]
+		if printer.ID == "" {
+			problems = append(problems, fmt.Sprintf("impressora #%d sem id", i+1))
+			continue
+		}
+		if ids[printer.ID] {
+			problems = append(problems, fmt.Sprintf("%s: id duplicado", printer.ID))
+		}
+		ids[printer.ID] = true
+
+		if printer.CodArm <= 0 {
+			problems = append(problems, fmt.Sprintf("%s: codArm obrigatório", printer.ID))
+		}
+		if printer.Padrao {
+			padroes[printer.CodArm]++
+		}
+
+		endereco := strings.TrimSpace(printer.Endereco)
+		if endereco == "" {
+			problems = append(problems, fmt.Sprintf("%s: endereco obrigatório", printer.ID))
+			continue
+		}
+		if _, _, err := net.SplitHostPort(endereco); err != nil {
+			endereco = net.JoinHostPort(endereco, defaultPrinterPort)
+		}
+		printer.Endereco = endereco
+	}
+
+	for codArm, n := range padroes {
+		if n > 1 {
+			problems = append(problems, fmt.Sprintf("armazém %d com %d impressoras padrão", codArm, n))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
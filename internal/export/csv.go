@@ -0,0 +1,46 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// BOM para o Excel abrir o arquivo como UTF-8
+const utf8BOM = "\ufeff"
+
+type csvWriter struct {
+	w      *csv.Writer
+	kinds  []Kind
+	record []string
+}
+
+func newCSVWriter(out io.Writer, headers []string, kinds []Kind) (*csvWriter, error) {
+	if _, err := io.WriteString(out, utf8BOM); err != nil {
+		return nil, err
+	}
+
+	w := csv.NewWriter(out)
+	w.Comma = ';' // Vírgula é o separador decimal
+	w.UseCRLF = true
+
+	if err := w.Write(headers); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: w, kinds: kinds, record: make([]string, len(kinds))}, nil
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		c.record[i] = formatText(c.kinds[i], v)
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
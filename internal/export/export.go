@@ -0,0 +1,137 @@
+// Package export gera planilhas CSV e XLSX em streaming, sem dependências externas.
+//
+// Os valores seguem o formato brasileiro: vírgula decimal e datas DD/MM/AAAA.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrFormatoInvalido = errors.New("formato de exportação inválido")
+	ErrColunaInvalida  = errors.New("coluna de exportação inválida")
+)
+
+// Format é o tipo de arquivo gerado
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat aceita "csv" (padrão quando vazio) ou "xlsx"
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("%w: '%s'", ErrFormatoInvalido, s)
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Kind define como o valor da coluna é formatado
+type Kind int
+
+const (
+	Text    Kind = iota
+	Integer      // int
+	Decimal      // float64
+	Date         // string vinda do ERP (DD/MM/AAAA ou ddMMyyyy HH:mm:ss)
+)
+
+// Column descreve uma coluna exportável de T
+type Column[T any] struct {
+	Key    string
+	Header string
+	Kind   Kind
+	Value  func(T) any
+}
+
+// SelectColumns devolve as colunas pedidas, na ordem pedida (todas quando keys é vazio)
+func SelectColumns[T any](all []Column[T], keys []string) ([]Column[T], error) {
+	if len(keys) == 0 {
+		return all, nil
+	}
+
+	byKey := make(map[string]Column[T], len(all))
+	for _, col := range all {
+		byKey[col.Key] = col
+	}
+
+	selected := make([]Column[T], 0, len(keys))
+	for _, key := range keys {
+		col, ok := byKey[strings.TrimSpace(key)]
+		if !ok {
+			return nil, fmt.Errorf("%w: '%s'", ErrColunaInvalida, key)
+		}
+		selected = append(selected, col)
+	}
+	return selected, nil
+}
+
+// Writer grava linhas já na ordem das colunas
+type Writer interface {
+	WriteRow(values []any) error
+	Flush() error
+	Close() error
+}
+
+// NewWriter cria o writer do formato e já grava o cabeçalho
+func NewWriter(format Format, out io.Writer, sheet string, headers []string, kinds []Kind) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(out, headers, kinds)
+	case FormatXLSX:
+		return newXLSXWriter(out, sheet, headers, kinds)
+	}
+	return nil, fmt.Errorf("%w: '%s'", ErrFormatoInvalido, format)
+}
+
+// Table liga as colunas de T a um Writer
+type Table[T any] struct {
+	cols   []Column[T]
+	w      Writer
+	values []any
+}
+
+func NewTable[T any](format Format, out io.Writer, sheet string, cols []Column[T]) (*Table[T], error) {
+	headers := make([]string, len(cols))
+	kinds := make([]Kind, len(cols))
+	for i, col := range cols {
+		headers[i] = col.Header
+		kinds[i] = col.Kind
+	}
+
+	w, err := NewWriter(format, out, sheet, headers, kinds)
+	if err != nil {
+		return nil, err
+	}
+	return &Table[T]{cols: cols, w: w, values: make([]any, len(cols))}, nil
+}
+
+func (t *Table[T]) Write(item T) error {
+	for i, col := range t.cols {
+		t.values[i] = col.Value(item)
+	}
+	return t.w.WriteRow(t.values)
+}
+
+func (t *Table[T]) Flush() error { return t.w.Flush() }
+
+func (t *Table[T]) Close() error { return t.w.Close() }
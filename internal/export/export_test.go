@@ -0,0 +1,240 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type linhaTeste struct {
+	Descricao string
+	CodProd   int
+	Qtd       float64
+	DatVal    string
+}
+
+var colunasTeste = []Column[linhaTeste]{
+	{Key: "descricao", Header: "Descrição", Kind: Text, Value: func(l linhaTeste) any { return l.Descricao }},
+	{Key: "codProd", Header: "Produto", Kind: Integer, Value: func(l linhaTeste) any { return l.CodProd }},
+	{Key: "qtd", Header: "Quantidade", Kind: Decimal, Value: func(l linhaTeste) any { return l.Qtd }},
+	{Key: "datVal", Header: "Validade", Kind: Date, Value: func(l linhaTeste) any { return l.DatVal }},
+}
+
+// xlsxSheet é o suficiente de sheet1.xml para conferir as células
+type xlsxSheet struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Style  string `xml:"s,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func writeTable(t *testing.T, format Format, sheet string, rows []linhaTeste) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	table, err := NewTable(format, &buf, sheet, colunasTeste)
+	if err != nil {
+		t.Fatalf("NewTable: %v", err)
+	}
+	for _, row := range rows {
+		if err := table.Write(row); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := table.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXTable(t *testing.T) {
+	data := writeTable(t, FormatXLSX, "Estoque [CD]", []linhaTeste{
+		{Descricao: "PARAFUSO <M6> & ARRUELA ÇÃO", CodProd: 5050, Qtd: 1234.5, DatVal: "31/12/2026"},
+		{Descricao: "", CodProd: 7, Qtd: 0.125, DatVal: "31122026 10:30:00"},
+		{Descricao: "SEM VALIDADE", CodProd: 8, Qtd: 1, DatVal: "sem data"},
+	})
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("abrir %s: %v", f.Name, err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("ler %s: %v", f.Name, err)
+		}
+		files[f.Name] = body
+
+		// Toda parte precisa ser XML bem formado
+		dec := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			if _, err := dec.Token(); err != nil {
+				if !errors.Is(err, io.EOF) {
+					t.Fatalf("%s não é XML válido: %v", f.Name, err)
+				}
+				break
+			}
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("parte %s ausente", name)
+		}
+	}
+	if !bytes.Contains(files["xl/workbook.xml"], []byte(`name="Estoque -CD-"`)) {
+		t.Errorf("nome da planilha não foi saneado: %s", files["xl/workbook.xml"])
+	}
+
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(files["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatalf("sheet1.xml: %v", err)
+	}
+	if len(sheet.Rows) != 4 {
+		t.Fatalf("linhas = %d, quer 4 (cabeçalho + 3)", len(sheet.Rows))
+	}
+
+	header := sheet.Rows[0]
+	if len(header.Cells) != 4 || header.Cells[0].Inline != "Descrição" || header.Cells[3].Ref != "D1" || header.Cells[0].Style != "3" {
+		t.Errorf("cabeçalho inesperado: %+v", header.Cells)
+	}
+
+	first := sheet.Rows[1].Cells
+	tests := []struct {
+		name                string
+		got                 string
+		want                string
+		gotStyle, wantStyle string
+		gotRef, wantRef     string
+	}{
+		{"texto escapado", first[0].Inline, "PARAFUSO <M6> & ARRUELA ÇÃO", first[0].Type, "inlineStr", first[0].Ref, "A2"},
+		{"inteiro", first[1].Value, "5050", first[1].Style, "", first[1].Ref, "B2"},
+		{"decimal com ponto no XML", first[2].Value, "1234.5", first[2].Style, "2", first[2].Ref, "C2"},
+		{"data serial de 31/12/2026", first[3].Value, "46387", first[3].Style, "1", first[3].Ref, "D2"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want || tt.gotStyle != tt.wantStyle || tt.gotRef != tt.wantRef {
+			t.Errorf("%s: valor %q estilo %q ref %q, quer %q %q %q", tt.name, tt.got, tt.gotStyle, tt.gotRef, tt.want, tt.wantStyle, tt.wantRef)
+		}
+	}
+
+	// Texto vazio não gera célula; data com hora do DbExplorer vira serial com fração
+	second := sheet.Rows[2].Cells
+	if len(second) != 3 || second[0].Ref != "B3" {
+		t.Errorf("linha com texto vazio: %+v", second)
+	} else if second[2].Value != "46387.4375" {
+		t.Errorf("data com hora = %q, quer 46387.4375", second[2].Value)
+	}
+
+	// Data não reconhecida sai como texto
+	third := sheet.Rows[3].Cells
+	if got := third[3]; got.Type != "inlineStr" || got.Inline != "sem data" {
+		t.Errorf("data inválida: %+v", got)
+	}
+}
+
+func TestCSVTable(t *testing.T) {
+	data := string(writeTable(t, FormatCSV, "", []linhaTeste{
+		{Descricao: "CAIXA; GRANDE", CodProd: 5050, Qtd: 1234.5, DatVal: "31122026 10:30:00"},
+	}))
+
+	want := utf8BOM +
+		"Descrição;Produto;Quantidade;Validade\r\n" +
+		"\"CAIXA; GRANDE\";5050;1234,5;31/12/2026\r\n"
+	if data != want {
+		t.Errorf("CSV =\n%q\nquer\n%q", data, want)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{0, "A"},
+		{1, "B"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := columnName(tt.i); got != tt.want {
+			t.Errorf("columnName(%d) = %q, quer %q", tt.i, got, tt.want)
+		}
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Estoque", "Estoque"},
+		{"  ", "Planilha1"},
+		{`a[b]c:d*e?f/g\h`, "a-b-c-d-e-f-g-h"},
+		{strings.Repeat("Ç", 40), strings.Repeat("Ç", 31)},
+	}
+	for _, tt := range tests {
+		if got := sheetName(tt.in); got != tt.want {
+			t.Errorf("sheetName(%q) = %q, quer %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want string
+	}{
+		{0, "0"},
+		{12, "12"},
+		{1234.5, "1234,5"},
+		{-0.125, "-0,125"},
+		{1000000.25, "1000000,25"},
+	}
+	for _, tt := range tests {
+		if got := FormatDecimal(tt.f); got != tt.want {
+			t.Errorf("FormatDecimal(%v) = %q, quer %q", tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"31/12/2026", "31/12/2026"},
+		{"31122026 10:30:00", "31/12/2026"},
+		{"31122026", "31/12/2026"},
+		{"2026-12-31T08:00:00", "31/12/2026"},
+		{"2026-12-31", "31/12/2026"},
+		{"", ""},
+		{"sem data", "sem data"},
+	}
+	for _, tt := range tests {
+		if got := FormatDate(tt.in); got != tt.want {
+			t.Errorf("FormatDate(%q) = %q, quer %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts em que as datas chegam do ERP (DbExplorer devolve ddMMyyyy HH:mm:ss)
+var dateLayouts = []string{
+	"02/01/2006",
+	"02/01/2006 15:04:05",
+	"02012006 15:04:05",
+	"02012006",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseDate interpreta uma data do ERP; ok é falso quando o formato é desconhecido
+func ParseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// FormatDate devolve DD/MM/AAAA, ou o texto original se não for uma data reconhecida
+func FormatDate(s string) string {
+	if t, ok := ParseDate(s); ok {
+		return t.Format("02/01/2006")
+	}
+	return s
+}
+
+// FormatDecimal usa vírgula decimal e nenhum separador de milhar, que é o que o
+// Excel em pt-BR importa como número sem ambiguidade
+func FormatDecimal(f float64) string {
+	return strings.Replace(strconv.FormatFloat(f, 'f', -1, 64), ".", ",", 1)
+}
+
+func toInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func toText(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// formatText aplica a regra de cada Kind para saídas textuais (CSV)
+func formatText(kind Kind, v any) string {
+	switch kind {
+	case Integer:
+		if n, ok := toInt(v); ok {
+			return strconv.FormatInt(n, 10)
+		}
+	case Decimal:
+		if f, ok := toFloat(v); ok {
+			return FormatDecimal(f)
+		}
+	case Date:
+		return FormatDate(toText(v))
+	}
+	return toText(v)
+}
@@ -0,0 +1,360 @@
+// Package gs1 decodifica leituras GS1-128 / GS1 DataMatrix (etiquetas de palete de fornecedores).
+package gs1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GroupSeparator é o caractere FNC1 transmitido pelos leitores (ASCII 29)
+const GroupSeparator = '\x1d'
+
+var (
+	ErrNotGS1       = errors.New("código não está no formato GS1")
+	ErrInvalidGTIN  = errors.New("GTIN com dígito verificador inválido")
+	ErrUnknownAI    = errors.New("identificador de aplicação (AI) desconhecido")
+	ErrTruncatedAI  = errors.New("leitura GS1 incompleta")
+	ErrInvalidValue = errors.New("valor inválido para o identificador de aplicação")
+)
+
+// Result contém os campos de interesse do WMS já convertidos
+type Result struct {
+	GTIN        string            // AI 01 (ou 02, conteúdo do palete)
+	SSCC        string            // AI 00
+	Lote        string            // AI 10
+	Fabricacao  time.Time         // AI 11
+	Validade    time.Time         // AI 17 (ou 15, consumir até)
+	Quantidade  float64           // AI 30 ou 37
+	PesoLiquido float64           // AI 310n (kg)
+	AIs         map[string]string // Todos os AIs lidos, em texto puro
+}
+
+// HasValidade indica se a etiqueta trouxe data de validade
+func (r *Result) HasValidade() bool {
+	return !r.Validade.IsZero()
+}
+
+// ValidadeBR retorna a validade no formato usado pelo Sankhya (DD/MM/YYYY)
+func (r *Result) ValidadeBR() string {
+	if r.Validade.IsZero() {
+		return ""
+	}
+	return r.Validade.Format("02/01/2006")
+}
+
+// GTINCandidates retorna as formas em que o GTIN pode estar cadastrado em TGFVOA.CODBARRA
+// (GTIN-14 completo e EAN-13/UPC-12 sem os zeros à esquerda)
+func (r *Result) GTINCandidates() []string {
+	if r.GTIN == "" {
+		return nil
+	}
+	candidates := []string{r.GTIN}
+	trimmed := r.GTIN
+	for len(trimmed) > 12 && trimmed[0] == '0' {
+		trimmed = trimmed[1:]
+		candidates = append(candidates, trimmed)
+	}
+	return candidates
+}
+
+// aiSpec descreve o tamanho de um AI: fixo (fixed > 0) ou variável até max
+type aiSpec struct {
+	fixed int
+	max   int
+}
+
+// aiTable cobre os AIs usados em etiquetas logísticas. AIs de 4 dígitos com casa decimal
+// (310n, 330n...) são tratados em lookupAI.
+var aiTable = map[string]aiSpec{
+	"00":  {fixed: 18},
+	"01":  {fixed: 14},
+	"02":  {fixed: 14},
+	"10":  {max: 20},
+	"11":  {fixed: 6},
+	"12":  {fixed: 6},
+	"13":  {fixed: 6},
+	"15":  {fixed: 6},
+	"16":  {fixed: 6},
+	"17":  {fixed: 6},
+	"20":  {fixed: 2},
+	"21":  {max: 20},
+	"22":  {max: 20},
+	"30":  {max: 8},
+	"37":  {max: 8},
+	"90":  {max: 30},
+	"240": {max: 30},
+	"241": {max: 30},
+	"400": {max: 30},
+	"401": {max: 30},
+	"410": {fixed: 13},
+	"411": {fixed: 13},
+	"412": {fixed: 13},
+	"413": {fixed: 13},
+	"414": {fixed: 13},
+	"415": {fixed: 13},
+}
+
+// lookupAI identifica o AI no início de s
+func lookupAI(s string) (string, aiSpec, bool) {
+	if len(s) >= 4 {
+		// Medidas (310n peso líquido kg, 330n peso bruto kg, etc.): 3 dígitos + casas decimais
+		prefix := s[:3]
+		if (prefix >= "310" && prefix <= "316") || (prefix >= "320" && prefix <= "336") {
+			if s[3] >= '0' && s[3] <= '9' {
+				return s[:4], aiSpec{fixed: 6}, true
+			}
+		}
+	}
+	if len(s) >= 3 {
+		if spec, ok := aiTable[s[:3]]; ok {
+			return s[:3], spec, true
+		}
+	}
+	if len(s) >= 2 {
+		if spec, ok := aiTable[s[:2]]; ok {
+			return s[:2], spec, true
+		}
+	}
+	return "", aiSpec{}, false
+}
+
+// IsGS1 faz uma verificação rápida (sem decodificar) se a leitura parece GS1
+func IsGS1(raw string) bool {
+	s := strings.TrimSpace(raw)
+	if hasSymbologyID(s) || strings.ContainsRune(s, GroupSeparator) || strings.HasPrefix(s, "(") {
+		return true
+	}
+	// Sem FNC1 visível: aceita apenas quando há algo além de um GTIN puro
+	return len(s) > 16 && (strings.HasPrefix(s, "01") || strings.HasPrefix(s, "02") || strings.HasPrefix(s, "00"))
+}
+
+func hasSymbologyID(s string) bool {
+	return strings.HasPrefix(s, "]C1") || strings.HasPrefix(s, "]d2") || strings.HasPrefix(s, "]Q3") || strings.HasPrefix(s, "]e0")
+}
+
+// Parse decodifica uma leitura bruta. Aceita o identificador de simbologia (]C1, ]d2...),
+// FNC1 como separador de grupo (ASCII 29) e o formato legível com parênteses "(01)...(17)...".
+func Parse(raw string) (*Result, error) {
+	s := strings.TrimSpace(raw)
+	if hasSymbologyID(s) {
+		s = s[3:]
+	}
+	s = strings.TrimLeft(s, string(GroupSeparator))
+	if s == "" {
+		return nil, ErrNotGS1
+	}
+
+	var fields map[string]string
+	var err error
+	if strings.HasPrefix(s, "(") {
+		fields, err = parseParenthesized(s)
+	} else {
+		fields, err = parseRaw(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buildResult(fields)
+}
+
+// parseRaw percorre a cadeia usando os tamanhos dos AIs e o separador de grupo
+func parseRaw(s string) (map[string]string, error) {
+	fields := map[string]string{}
+	for len(s) > 0 {
+		if s[0] == GroupSeparator {
+			s = s[1:]
+			continue
+		}
+
+		ai, spec, ok := lookupAI(s)
+		if !ok {
+			if len(fields) == 0 {
+				return nil, ErrNotGS1
+			}
+			return nil, fmt.Errorf("%w: %.4s", ErrUnknownAI, s)
+		}
+		s = s[len(ai):]
+
+		var value string
+		if spec.fixed > 0 {
+			if len(s) < spec.fixed {
+				return nil, fmt.Errorf("%w: AI %s", ErrTruncatedAI, ai)
+			}
+			value = s[:spec.fixed]
+			s = s[spec.fixed:]
+		} else {
+			end := strings.IndexRune(s, GroupSeparator)
+			if end < 0 {
+				end = len(s)
+			}
+			if end > spec.max {
+				return nil, fmt.Errorf("%w: AI %s excede %d caracteres", ErrInvalidValue, ai, spec.max)
+			}
+			value = s[:end]
+			s = s[end:]
+		}
+
+		fields[ai] = value
+	}
+	return fields, nil
+}
+
+// parseParenthesized trata o formato legível, ex: (01)07891234567895(17)261231(10)L123
+func parseParenthesized(s string) (map[string]string, error) {
+	fields := map[string]string{}
+	for len(s) > 0 {
+		if s[0] != '(' {
+			return nil, ErrNotGS1
+		}
+		closeIdx := strings.IndexByte(s, ')')
+		if closeIdx < 0 {
+			return nil, ErrTruncatedAI
+		}
+		ai := s[1:closeIdx]
+		s = s[closeIdx+1:]
+
+		end := strings.IndexByte(s, '(')
+		if end < 0 {
+			end = len(s)
+		}
+		value := s[:end]
+		s = s[end:]
+
+		gotAI, spec, ok := lookupAI(ai + value)
+		if !ok || gotAI != ai {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAI, ai)
+		}
+		if spec.fixed > 0 && len(value) != spec.fixed {
+			return nil, fmt.Errorf("%w: AI %s deve ter %d caracteres", ErrInvalidValue, ai, spec.fixed)
+		}
+		if spec.max > 0 && len(value) > spec.max {
+			return nil, fmt.Errorf("%w: AI %s excede %d caracteres", ErrInvalidValue, ai, spec.max)
+		}
+		fields[ai] = value
+	}
+	return fields, nil
+}
+
+func buildResult(fields map[string]string) (*Result, error) {
+	res := &Result{AIs: fields}
+
+	if v, ok := fields["01"]; ok {
+		res.GTIN = v
+	} else if v, ok := fields["02"]; ok {
+		res.GTIN = v
+	}
+	if res.GTIN != "" && !validCheckDigit(res.GTIN) {
+		return nil, ErrInvalidGTIN
+	}
+
+	res.SSCC = fields["00"]
+	res.Lote = fields["10"]
+
+	var err error
+	if v, ok := fields["11"]; ok {
+		if res.Fabricacao, err = parseDate(v); err != nil {
+			return nil, fmt.Errorf("%w: AI 11", err)
+		}
+	}
+	// 17 (validade) tem prioridade sobre 15 (consumir preferencialmente até)
+	for _, ai := range []string{"15", "17"} {
+		if v, ok := fields[ai]; ok {
+			if res.Validade, err = parseDate(v); err != nil {
+				return nil, fmt.Errorf("%w: AI %s", err, ai)
+			}
+		}
+	}
+
+	for _, ai := range []string{"30", "37"} {
+		if v, ok := fields[ai]; ok {
+			q, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: AI %s", ErrInvalidValue, ai)
+			}
+			res.Quantidade = float64(q)
+		}
+	}
+
+	for ai, v := range fields {
+		if len(ai) == 4 && strings.HasPrefix(ai, "310") {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: AI %s", ErrInvalidValue, ai)
+			}
+			decimals := int(ai[3] - '0')
+			res.PesoLiquido = float64(n)
+			for i := 0; i < decimals; i++ {
+				res.PesoLiquido /= 10
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// parseDate converte YYMMDD. Dia 00 significa o último dia do mês (regra GS1).
+func parseDate(v string) (time.Time, error) {
+	if len(v) != 6 {
+		return time.Time{}, ErrInvalidValue
+	}
+	yy, err1 := strconv.Atoi(v[0:2])
+	mm, err2 := strconv.Atoi(v[2:4])
+	dd, err3 := strconv.Atoi(v[4:6])
+	if err1 != nil || err2 != nil || err3 != nil || mm < 1 || mm > 12 || dd > 31 {
+		return time.Time{}, ErrInvalidValue
+	}
+
+	year := resolveCentury(yy, time.Now().Year())
+	if dd == 0 {
+		// Dia 0 do mês seguinte = último dia do mês
+		return time.Date(year, time.Month(mm)+1, 0, 0, 0, 0, 0, time.Local), nil
+	}
+
+	t := time.Date(year, time.Month(mm), dd, 0, 0, 0, 0, time.Local)
+	if t.Day() != dd {
+		return time.Time{}, ErrInvalidValue
+	}
+	return t, nil
+}
+
+// resolveCentury aplica a janela deslizante da especificação GS1 (-49 a +50 anos)
+func resolveCentury(yy int, currentYear int) int {
+	century := currentYear / 100 * 100
+	diff := yy - currentYear%100
+	switch {
+	case diff >= 51 && diff <= 99:
+		return century - 100 + yy
+	case diff >= -99 && diff <= -50:
+		return century + 100 + yy
+	default:
+		return century + yy
+	}
+}
+
+// validCheckDigit valida o dígito verificador (módulo 10) de GTIN/SSCC
+func validCheckDigit(code string) bool {
+	if len(code) < 2 {
+		return false
+	}
+	sum := 0
+	weight := 3
+	for i := len(code) - 2; i >= 0; i-- {
+		d := code[i]
+		if d < '0' || d > '9' {
+			return false
+		}
+		sum += int(d-'0') * weight
+		if weight == 3 {
+			weight = 1
+		} else {
+			weight = 3
+		}
+	}
+	check := (10 - sum%10) % 10
+	last := code[len(code)-1]
+	return last >= '0' && last <= '9' && int(last-'0') == check
+}
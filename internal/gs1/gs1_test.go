@@ -0,0 +1,229 @@
+package gs1
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const gs = string(GroupSeparator)
+
+func TestValidCheckDigit(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"GTIN-14 válido", "07891234567895", true},
+		{"GTIN-14 com indicador logístico", "17891234567892", true},
+		{"GTIN-14 dígito errado", "07891234567896", false},
+		{"GTIN-14 indicador trocado", "17891234567895", false},
+		{"EAN-13 válido", "7891234567895", true},
+		{"SSCC válido", "000000000000000017", true},
+		{"caractere não numérico", "0789123456789A", false},
+		{"curto demais", "5", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validCheckDigit(tt.code); got != tt.want {
+				t.Errorf("validCheckDigit(%q) = %v, quer %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name       string
+		raw        string
+		gtin       string
+		lote       string
+		validade   time.Time
+		quantidade float64
+		peso       float64
+		ais        map[string]string
+	}{
+		{
+			name:     "GTIN-14 e validade",
+			raw:      "]C1010789123456789517261231",
+			gtin:     "07891234567895",
+			validade: date(2026, time.December, 31),
+		},
+		{
+			name:     "lote variável terminado em GS antes da validade",
+			raw:      "]C10107891234567895" + "10L123" + gs + "17270615",
+			gtin:     "07891234567895",
+			lote:     "L123",
+			validade: date(2027, time.June, 15),
+		},
+		{
+			name:     "lote variável no fim da leitura, sem GS",
+			raw:      "0107891234567895" + "17270615" + "10ABC-99",
+			gtin:     "07891234567895",
+			lote:     "ABC-99",
+			validade: date(2027, time.June, 15),
+		},
+		{
+			name:       "dois variáveis seguidos (30 e 10) separados por GS",
+			raw:        "]d2" + "0217891234567892" + "3048" + gs + "10LOTE7" + gs,
+			gtin:       "17891234567892",
+			lote:       "LOTE7",
+			quantidade: 48,
+		},
+		{
+			name:     "AI 17 com dia 00 vira último dia do mês",
+			raw:      "0107891234567895" + "17270200",
+			gtin:     "07891234567895",
+			validade: date(2027, time.February, 28),
+		},
+		{
+			name:     "AI 17 dia 00 em ano bissexto",
+			raw:      "(01)07891234567895(17)280200",
+			gtin:     "07891234567895",
+			validade: date(2028, time.February, 29),
+		},
+		{
+			name:     "AI 17 tem prioridade sobre 15",
+			raw:      "(01)07891234567895(15)261130(17)261231",
+			gtin:     "07891234567895",
+			validade: date(2026, time.December, 31),
+		},
+		{
+			name:       "AI 37 com zeros à esquerda",
+			raw:        "(00)000000000000000017(02)17891234567892(37)0012",
+			gtin:       "17891234567892",
+			quantidade: 12,
+		},
+		{
+			name:       "AI 30 e peso líquido 3103",
+			raw:        "0107891234567895" + "3103012500" + "3012",
+			gtin:       "07891234567895",
+			quantidade: 12,
+			peso:       12.5,
+		},
+		{
+			name: "todos os AIs ficam em AIs",
+			raw:  "(01)07891234567895(10)L1(21)SERIE9",
+			gtin: "07891234567895",
+			lote: "L1",
+			ais:  map[string]string{"01": "07891234567895", "10": "L1", "21": "SERIE9"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) erro inesperado: %v", tt.raw, err)
+			}
+			if res.GTIN != tt.gtin {
+				t.Errorf("GTIN = %q, quer %q", res.GTIN, tt.gtin)
+			}
+			if res.Lote != tt.lote {
+				t.Errorf("Lote = %q, quer %q", res.Lote, tt.lote)
+			}
+			if !res.Validade.Equal(tt.validade) {
+				t.Errorf("Validade = %v, quer %v", res.Validade, tt.validade)
+			}
+			if res.Quantidade != tt.quantidade {
+				t.Errorf("Quantidade = %v, quer %v", res.Quantidade, tt.quantidade)
+			}
+			if res.PesoLiquido != tt.peso {
+				t.Errorf("PesoLiquido = %v, quer %v", res.PesoLiquido, tt.peso)
+			}
+			if tt.ais != nil && !reflect.DeepEqual(res.AIs, tt.ais) {
+				t.Errorf("AIs = %v, quer %v", res.AIs, tt.ais)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want error
+	}{
+		{"vazio", "]C1", ErrNotGS1},
+		{"GTIN com dígito errado", "0107891234567896", ErrInvalidGTIN},
+		{"AI fixo truncado", "01078912345678", ErrTruncatedAI},
+		{"AI desconhecido após o GTIN", "0107891234567895" + "99X", ErrUnknownAI},
+		{"lote maior que 20 sem GS", "0107891234567895" + "10ABCDEFGHIJKLMNOPQRSTU", ErrInvalidValue},
+		{"quantidade AI 30 não numérica", "0107891234567895" + "3012A" + gs, ErrInvalidValue},
+		{"AI 37 maior que 8 dígitos", "(37)123456789", ErrInvalidValue},
+		{"validade com mês 13", "0107891234567895" + "17261301", ErrInvalidValue},
+		{"validade 31/02", "(17)260231", ErrInvalidValue},
+		{"AI fixo com tamanho errado entre parênteses", "(17)2612", ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.raw)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) erro = %v, quer %v", tt.raw, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGTINCandidates(t *testing.T) {
+	tests := []struct {
+		gtin string
+		want []string
+	}{
+		{"17891234567892", []string{"17891234567892"}},
+		{"07891234567895", []string{"07891234567895", "7891234567895"}},
+		{"00012345678905", []string{"00012345678905", "0012345678905", "012345678905"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gtin, func(t *testing.T) {
+			r := &Result{GTIN: tt.gtin}
+			if got := r.GTINCandidates(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GTINCandidates() = %v, quer %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveCentury(t *testing.T) {
+	tests := []struct {
+		yy, current, want int
+	}{
+		{26, 2026, 2026},
+		{76, 2026, 2076},
+		{77, 2026, 1977},
+		{99, 2026, 1999},
+		{1, 2098, 2101},
+		{49, 2098, 2049},
+	}
+	for _, tt := range tests {
+		if got := resolveCentury(tt.yy, tt.current); got != tt.want {
+			t.Errorf("resolveCentury(%d, %d) = %d, quer %d", tt.yy, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestIsGS1(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"]C1010789123456789517261231", true},
+		{"(01)07891234567895", true},
+		{"10L1" + gs + "3012", true},
+		{"010789123456789517261231", true},
+		{"07891234567895", false},
+		{"7891234567895", false},
+		{"12345", false},
+	}
+	for _, tt := range tests {
+		if got := IsGS1(tt.raw); got != tt.want {
+			t.Errorf("IsGS1(%q) = %v, quer %v", tt.raw, got, tt.want)
+		}
+	}
+}
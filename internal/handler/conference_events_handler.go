@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"zenith-go/internal/auth"
+	"zenith-go/internal/conference"
+)
+
+// sseHeartbeat mantém a conexão viva atrás do NGINX (proxy_read_timeout) e revalida a sessão
+const sseHeartbeat = 25 * time.Second
+
+// HandleConferenceEventsTicket emite o ticket de uso único para abrir o stream pelo EventSource
+func (h *RomaneioHandler) HandleConferenceEventsTicket(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderRomaneio(r)
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return
+	}
+
+	if _, _, err := auth.ValidateToken(token, h.Config.JwtSecret); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	ticket, err := h.Events.IssueTicket(ctx, token)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Eventos de conferência indisponíveis", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"ticket":   ticket,
+		"expiraEm": int(conference.TicketTTL.Seconds()),
+	})
+}
+
+// HandleConferenceEvents abre um stream SSE com os eventos da conferência de um romaneio
+// (nu_unico na query; sem ele, de todos os romaneios, para o painel).
+// EventSource não envia headers: nesse caso vale o ticket de uso único na query (?ticket=),
+// nunca o JWT, que ficaria nos logs de acesso.
+func (h *RomaneioHandler) HandleConferenceEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderRomaneio(r)
+	if token == "" {
+		if ticket := r.URL.Query().Get("ticket"); ticket != "" {
+			var err error
+			if token, err = h.Events.RedeemTicket(r.Context(), ticket); err != nil {
+				if errors.Is(err, conference.ErrTicketInvalido) {
+					RespondError(w, r, h.Notifier, http.StatusUnauthorized, err.Error(), nil)
+				} else {
+					RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Eventos de conferência indisponíveis", err)
+				}
+				return
+			}
+		}
+	}
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return
+	}
+
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	nuUnico := 0
+	if v := r.URL.Query().Get("nu_unico"); v != "" {
+		if nuUnico, err = strconv.Atoi(v); err != nil || nuUnico < 0 {
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, "Parâmetro 'nu_unico' inválido", nil)
+			return
+		}
+	}
+
+	// O stream termina quando o cliente desconecta ou quando o servidor começa a desligar
+	// (srv.Shutdown não espera conexões abertas indefinidamente)
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	if h.Streams != nil {
+		stop := context.AfterFunc(h.Streams, cancel)
+		defer stop()
+	}
+
+	sub, err := h.Events.Subscribe(ctx, nuUnico)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Eventos de conferência indisponíveis", err)
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // NGINX não segura os eventos no buffer
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 5000\n: conectado\n\n")
+	if err := rc.Flush(); err != nil {
+		slog.Error("Stream de conferência sem suporte a flush", "error", err)
+		return
+	}
+	slog.Info("Stream de conferência aberto", "codUsu", codUsu, "nuUnico", nuUnico)
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case e, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Tipo, data)
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			// Só consulta: o stream aberto não deve manter a sessão viva sozinho
+			if _, err := h.Session.GetSankhyaSession(token); errors.Is(err, auth.ErrSessionExpired) {
+				fmt.Fprint(w, "event: sessao-expirada\ndata: {}\n\n")
+				rc.Flush()
+				return
+			}
+			fmt.Fprint(w, ": ping\n\n")
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// publishConference publica o evento para os inscritos no romaneio
+func (h *RomaneioHandler) publishConference(tipo string, nuUnico, codUsu int, username string, dados any) {
+	h.Events.Notify(conference.Event{Tipo: tipo, NuUnico: nuUnico, CodUsu: codUsu, Username: username, Dados: dados})
+}
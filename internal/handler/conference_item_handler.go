@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"zenith-go/internal/auth"
+	"zenith-go/internal/conference"
+	"zenith-go/internal/sankhya"
+)
+
+// HandleAjustarItemConferencia estorna ou corrige a quantidade de uma linha já conferida,
+// com motivo, enquanto a conferência não foi finalizada. Exige a posse da conferência.
+func (h *RomaneioHandler) HandleAjustarItemConferencia(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 1. Extração e Validação dos Tokens
+	bearerToken := getTokenFromHeaderRomaneio(r)
+	snkSessionId := getHeaderRomaneio(r, "Snkjsessionid")
+
+	if bearerToken == "" || snkSessionId == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Tokens ausentes (Authorization ou Snkjsessionid)", nil)
+		return
+	}
+
+	codUsu, username, err := auth.ValidateToken(bearerToken, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+
+	if err := h.Session.ValidateAndUpdate(bearerToken); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	// 2. Parsing do Body
+	var input sankhya.AjustarItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	if input.NuUnico == 0 || input.NumReg == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "Os campos 'nu_unico' e 'num_reg' são obrigatórios", nil)
+		return
+	}
+
+	// 3. Posse da conferência: só quem está conferindo altera as linhas
+	ctx := r.Context()
+	if _, ok := h.claimConference(ctx, w, r, input.NuUnico, conferenceHolder(r, bearerToken, codUsu, username)); !ok {
+		return
+	}
+
+	// 4. Validação do estado + alteração + auditoria
+	result, err := h.Client.AjustarItemConferencia(ctx, input, codUsu, snkSessionId)
+	if err != nil {
+		switch {
+		case errors.Is(err, sankhya.ErrConfAcaoInvalida), errors.Is(err, sankhya.ErrConfQuantidadeInvalida), errors.Is(err, sankhya.ErrConfMotivo):
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, sankhya.ErrConfNaoEncontrada), errors.Is(err, sankhya.ErrConfItemNaoEncontrado):
+			RespondError(w, r, h.Notifier, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, sankhya.ErrConfFinalizada):
+			RespondConferenceState(w, r, ErrCodeConfFinalizada, err)
+		case errors.Is(err, sankhya.ErrConfItemNaoConferido):
+			RespondConferenceState(w, r, ErrCodeConfNaoConferido, err)
+		default:
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao alterar item da conferência", err)
+		}
+		return
+	}
+
+	tipo := conference.EventoItemAjustado
+	if result.Acao == sankhya.AjusteEstornar {
+		tipo = conference.EventoItemEstornado
+	}
+	h.publishConference(tipo, input.NuUnico, codUsu, username, conference.ItemAjustado{
+		NumReg:       input.NumReg,
+		CodProd:      result.Linha.CodProd,
+		Unidade:      result.Linha.Unidade,
+		QtdAnterior:  result.QtdAnterior,
+		QtdEmbarcada: result.QtdEmbarcada,
+		Motivo:       strings.TrimSpace(input.Motivo),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// HandleConferenceItemHistory lista todas as alterações de uma linha da conferência
+// (conferências, estornos e ajustes), com usuário, horário e quantidades antes/depois
+func (h *RomaneioHandler) HandleConferenceItemHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeader(r)
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return
+	}
+
+	if _, _, err := auth.ValidateToken(token, h.Config.JwtSecret); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.ConferenceItemHistoryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	if input.NuUnico == 0 || input.NumReg == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "Os campos 'nu_unico' e 'num_reg' são obrigatórios", nil)
+		return
+	}
+
+	history, err := h.Client.GetConferenceItemHistory(ctx, input.NuUnico, input.NumReg)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar histórico do item", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"nuUnico":   input.NuUnico,
+		"numReg":    input.NumReg,
+		"historico": history,
+	})
+}
+
+// logConferenceItem registra a conferência da linha no histórico (AD_ZNTCONFLOG).
+// A linha já foi gravada pela STP, então uma falha aqui só é registrada.
+func (h *RomaneioHandler) logConferenceItem(ctx context.Context, nuUnico, numReg, codUsu int, qtdAnt *float64, qtdNova float64, obs string) {
+	entry := sankhya.ConferenceLogEntry{
+		NuUnico: nuUnico,
+		NumReg:  numReg,
+		Evento:  sankhya.ConfEventoItemConferido,
+		CodUsu:  codUsu,
+		Motivo:  strings.TrimSpace(obs),
+		QtdAnt:  qtdAnt,
+		QtdNova: &qtdNova,
+	}
+	if err := h.Client.LogConferenceEvent(ctx, entry); err != nil {
+		slog.Error("Falha ao gravar auditoria da conferência", "nuUnico", nuUnico, "numReg", numReg, "evento", entry.Evento, "error", err)
+	}
+}
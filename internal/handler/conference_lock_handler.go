@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"zenith-go/internal/auth"
+	"zenith-go/internal/conference"
+	"zenith-go/internal/sankhya"
+)
+
+// conferenceLockRequest é o corpo das rotas de posse da conferência
+type conferenceLockRequest struct {
+	NuUnico int    `json:"nu_unico"`
+	CodUsu  int    `json:"cod_usu"` // Transferência: novo operador
+	Motivo  string `json:"motivo"`
+}
+
+// conferenceHolder identifica o operador e o coletor. O coletor vem do header Devicetoken
+// (o mesmo deviceToken do login); sem ele, a sessão do JWT faz o papel de coletor.
+// Só o hash é guardado, o token do dispositivo não aparece nas respostas.
+func conferenceHolder(r *http.Request, bearerToken string, codUsu int, username string) conference.Holder {
+	source := "sessao:" + bearerToken
+	if device := strings.TrimSpace(r.Header.Get("Devicetoken")); device != "" {
+		source = "device:" + device
+	}
+	sum := sha256.Sum256([]byte(source))
+	return conference.Holder{CodUsu: codUsu, Username: username, Device: hex.EncodeToString(sum[:8])}
+}
+
+// claimConference pega ou renova a posse do romaneio; responde 409/503 e retorna false se negada.
+// created indica que a posse não existia (para desfazer se a operação no ERP falhar).
+func (h *RomaneioHandler) claimConference(ctx context.Context, w http.ResponseWriter, r *http.Request, nuUnico int, holder conference.Holder) (created bool, ok bool) {
+	_, created, err := h.Locks.Acquire(ctx, nuUnico, holder)
+	if err != nil {
+		var lockErr *conference.LockError
+		if errors.As(err, &lockErr) {
+			RespondConferenceLocked(w, r, lockErr)
+		} else {
+			RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Controle de conferência indisponível", err)
+		}
+		return false, false
+	}
+	return created, true
+}
+
+// decodeConferenceLock autentica e lê o corpo das rotas de posse
+func (h *RomaneioHandler) decodeConferenceLock(w http.ResponseWriter, r *http.Request) (codUsu int, input conferenceLockRequest, ok bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return 0, input, false
+	}
+
+	token := getTokenFromHeaderRomaneio(r)
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return 0, input, false
+	}
+
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return 0, input, false
+	}
+
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return 0, input, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return 0, input, false
+	}
+
+	if input.NuUnico == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "O campo 'nu_unico' é obrigatório", nil)
+		return 0, input, false
+	}
+	return codUsu, input, true
+}
+
+// requireSupervisor responde 403 quando o usuário não tem SUPCONF
+func (h *RomaneioHandler) requireSupervisor(ctx context.Context, w http.ResponseWriter, r *http.Request, codUsu int) bool {
+	perms, err := h.Client.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao verificar permissões", err)
+		return false
+	}
+	if !perms.SupConf {
+		RespondError(w, r, h.Notifier, http.StatusForbidden, "Apenas supervisores de conferência (SUPCONF) podem transferir ou liberar a conferência", nil)
+		return false
+	}
+	return true
+}
+
+// HandleGetConferenceLock informa quem está com a conferência do romaneio
+func (h *RomaneioHandler) HandleGetConferenceLock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	codUsu, input, ok := h.decodeConferenceLock(w, r)
+	if !ok {
+		return
+	}
+
+	lease, err := h.Locks.Get(ctx, input.NuUnico)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Controle de conferência indisponível", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"nuUnico": input.NuUnico,
+		"livre":   lease == nil,
+		"minha":   lease != nil && lease.CodUsu == codUsu,
+		"posse":   lease,
+	})
+}
+
+// HandleTransferConferenceLock passa a conferência para outro operador (SUPCONF).
+// O coletor do novo operador assume a posse na primeira leitura.
+func (h *RomaneioHandler) HandleTransferConferenceLock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	codUsu, input, ok := h.decodeConferenceLock(w, r)
+	if !ok {
+		return
+	}
+	if input.CodUsu <= 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "O campo 'cod_usu' é obrigatório", nil)
+		return
+	}
+	if !h.requireSupervisor(ctx, w, r, codUsu) {
+		return
+	}
+
+	previous, err := h.Locks.Get(ctx, input.NuUnico)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Controle de conferência indisponível", err)
+		return
+	}
+
+	lease, err := h.Locks.Transfer(ctx, input.NuUnico, conference.Holder{CodUsu: input.CodUsu})
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Controle de conferência indisponível", err)
+		return
+	}
+
+	detalhe := fmt.Sprintf("para usuário %d (conferência livre)", input.CodUsu)
+	if previous != nil {
+		detalhe = fmt.Sprintf("de usuário %d (%s, coletor %s) para usuário %d", previous.CodUsu, previous.Username, previous.Device, input.CodUsu)
+	}
+	h.logConferenceLock(ctx, sankhya.ConfEventoPosseTransferida, input, codUsu, detalhe)
+	h.publishConference(conference.EventoPosseAlterada, input.NuUnico, codUsu, "", map[string]any{"acao": "transferida", "posse": lease})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"nuUnico": input.NuUnico,
+		"posse":   lease,
+	})
+}
+
+// HandleReleaseConferenceLock libera a conferência presa a um coletor (SUPCONF)
+func (h *RomaneioHandler) HandleReleaseConferenceLock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	codUsu, input, ok := h.decodeConferenceLock(w, r)
+	if !ok {
+		return
+	}
+	if !h.requireSupervisor(ctx, w, r, codUsu) {
+		return
+	}
+
+	previous, err := h.Locks.Get(ctx, input.NuUnico)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Controle de conferência indisponível", err)
+		return
+	}
+	if previous != nil {
+		if err := h.Locks.Release(ctx, input.NuUnico); err != nil {
+			RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Controle de conferência indisponível", err)
+			return
+		}
+		detalhe := fmt.Sprintf("usuário %d (%s, coletor %s)", previous.CodUsu, previous.Username, previous.Device)
+		h.logConferenceLock(ctx, sankhya.ConfEventoPosseLiberada, input, codUsu, detalhe)
+		h.publishConference(conference.EventoPosseAlterada, input.NuUnico, codUsu, "", map[string]any{"acao": "liberada"})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"nuUnico":  input.NuUnico,
+		"liberado": previous != nil,
+	})
+}
+
+// logConferenceLock grava a intervenção do supervisor em AD_ZNTCONFLOG (falha só é registrada)
+func (h *RomaneioHandler) logConferenceLock(ctx context.Context, evento string, input conferenceLockRequest, codUsu int, detalhe string) {
+	slog.Info("Posse da conferência alterada por supervisor", "evento", evento, "nuUnico", input.NuUnico, "supervisor", codUsu, "detalhe", detalhe)
+	entry := sankhya.ConferenceLogEntry{
+		NuUnico: input.NuUnico,
+		Evento:  evento,
+		CodUsu:  codUsu,
+		Motivo:  strings.TrimSpace(input.Motivo),
+		Detalhe: detalhe,
+	}
+	if err := h.Client.LogConferenceEvent(ctx, entry); err != nil {
+		slog.Error("Falha ao gravar auditoria da conferência", "nuUnico", input.NuUnico, "evento", evento, "error", err)
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	"zenith-go/internal/auth"
+	"zenith-go/internal/sankhya"
+)
+
+// requireAprovador valida o JWT/sessão e confirma a permissão APRCORRE do usuário
+func (h *TransactionHandler) requireAprovador(ctx context.Context, w http.ResponseWriter, r *http.Request, token string) (*sankhya.UserPermissions, string, bool) {
+	codUsu, username, err := auth.ValidateToken(token, h.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return nil, "", false
+	}
+
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return nil, "", false
+	}
+
+	perms, err := h.Client.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar permissões", err)
+		return nil, "", false
+	}
+	if !perms.AprCorre {
+		RespondError(w, r, h.Notifier, http.StatusForbidden, "Usuário sem permissão para aprovar correções (APRCORRE)", nil)
+		return nil, "", false
+	}
+
+	return perms, username, true
+}
+
+// correcaoLockTTL cobre o timeout da aprovação (60s) com folga
+const correcaoLockTTL = 2 * time.Minute
+
+// lockCorrecao serializa aprovar/rejeitar por NUCORR entre os nós: sem isso, dois supervisores
+// (ou um duplo toque) leem STATUS = 'P' ao mesmo tempo e aplicam a correção duas vezes.
+// Responde 409/503 e retorna false se outro processamento estiver em andamento.
+func (h *TransactionHandler) lockCorrecao(ctx context.Context, w http.ResponseWriter, r *http.Request, nuCorr int) (release func(), ok bool) {
+	key := fmt.Sprintf("corr:%d", nuCorr)
+	acquired, err := h.Session.AcquireLock(ctx, key, correcaoLockTTL)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Controle de aprovação indisponível", err)
+		return nil, false
+	}
+	if !acquired {
+		RespondError(w, r, h.Notifier, http.StatusConflict, "Esta correção já está sendo processada", nil)
+		return nil, false
+	}
+	return func() {
+		// Se falhar, o lock expira sozinho no ttl
+		if err := h.Session.ReleaseLock(context.WithoutCancel(ctx), key); err != nil {
+			slog.Warn("Falha ao liberar lock da correção", "nucorr", nuCorr, "error", err)
+		}
+	}, true
+}
+
+// HandleListCorrecoesPendentes lista as correções aguardando aprovação
+func (h *TransactionHandler) HandleListCorrecoesPendentes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderTrans(r)
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return
+	}
+
+	perms, _, ok := h.requireAprovador(ctx, w, r, token)
+	if !ok {
+		return
+	}
+
+	pendentes, err := h.Client.ListCorrecoesPendentes(ctx, perms.Armazens())
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao listar correções pendentes", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pendentes)
+}
+
+// HandleAprovarCorrecao aplica uma correção pendente usando a sessão Sankhya do supervisor
+func (h *TransactionHandler) HandleAprovarCorrecao(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bearerToken := getTokenFromHeaderTrans(r)
+	snkSessionId := getHeader(r, "Snkjsessionid")
+
+	if bearerToken == "" || snkSessionId == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Tokens ausentes", nil)
+		return
+	}
+
+	perms, username, ok := h.requireAprovador(ctx, w, r, bearerToken)
+	if !ok {
+		return
+	}
+	codUsu := perms.CodUsu
+
+	var input sankhya.AprovarCorrecaoInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	if input.NuCorr == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "O campo 'nuCorr' é obrigatório", nil)
+		return
+	}
+
+	release, ok := h.lockCorrecao(ctx, w, r, input.NuCorr)
+	if !ok {
+		return
+	}
+	defer release()
+
+	msg, err := h.Client.AprovarCorrecao(ctx, input.NuCorr, codUsu, snkSessionId)
+	if err != nil {
+		switch {
+		case errors.Is(err, sankhya.ErrUserSessionExpired):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]any{
+				"error":          "Sessão Sankhya expirada. Por favor, faça login novamente.",
+				"reauthRequired": true,
+			})
+		case errors.Is(err, sankhya.ErrWarehouseNotAllowed):
+			RespondWarehouseForbidden(w, r, err)
+		case errors.Is(err, sankhya.ErrCorrecaoNaoEncontrada):
+			RespondError(w, r, h.Notifier, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, sankhya.ErrCorrecaoDesatualizada):
+			RespondError(w, r, h.Notifier, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, sankhya.ErrCorrecaoStatus):
+			meta := ErrorMeta{CodUsu: codUsu, Username: username, SessionID: snkSessionId}
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, sankhya.ErrCorrecaoStatus.Error(), err, input, meta)
+		default:
+			meta := ErrorMeta{CodUsu: codUsu, Username: username, SessionID: snkSessionId}
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Falha ao aprovar correção", err, input, meta)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": msg})
+}
+
+// HandleRejeitarCorrecao encerra uma correção pendente sem alterar o estoque
+func (h *TransactionHandler) HandleRejeitarCorrecao(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderTrans(r)
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return
+	}
+
+	perms, _, ok := h.requireAprovador(ctx, w, r, token)
+	if !ok {
+		return
+	}
+	codUsu := perms.CodUsu
+
+	var input sankhya.RejeitarCorrecaoInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	if input.NuCorr == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "O campo 'nuCorr' é obrigatório", nil)
+		return
+	}
+
+	release, ok := h.lockCorrecao(ctx, w, r, input.NuCorr)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if err := h.Client.RejeitarCorrecao(ctx, input.NuCorr, codUsu, input.Motivo); err != nil {
+		if errors.Is(err, sankhya.ErrWarehouseNotAllowed) {
+			RespondWarehouseForbidden(w, r, err)
+		} else if errors.Is(err, sankhya.ErrCorrecaoNaoEncontrada) {
+			RespondError(w, r, h.Notifier, http.StatusNotFound, err.Error(), nil)
+		} else {
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Falha ao rejeitar correção", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Correção rejeitada."})
+}
@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	"zenith-go/internal/auth"
+	"zenith-go/internal/config"
+	"zenith-go/internal/export"
+	"zenith-go/internal/notification"
+	"zenith-go/internal/sankhya"
+)
+
+// Tamanho da página usada para ler os dados do ERP durante a exportação
+const exportPageSize = 500
+
+type ExportHandler struct {
+	Client   *sankhya.Client
+	Config   *config.Config
+	Session  *auth.SessionManager
+	Notifier *notification.EmailService
+}
+
+type exportOptions struct {
+	Format  string   `json:"format"`  // csv (padrão) ou xlsx
+	Columns []string `json:"columns"` // Vazio = todas, na ordem padrão
+}
+
+type exportHistoryInput struct {
+	exportOptions
+	sankhya.HistoryFilter
+}
+
+type exportStockInput struct {
+	exportOptions
+	CodArm int    `json:"codArm"`
+	Filtro string `json:"filtro"`
+	Sort   string `json:"sort"`
+	Dir    string `json:"dir"`
+}
+
+type exportRomaneioInput struct {
+	exportOptions
+	NumeroFechamento int `json:"numero_fechamento"`
+}
+
+// romaneioExportRow achata cabeçalho + produto em uma linha da planilha
+type romaneioExportRow struct {
+	Header *sankhya.RomaneioDetalheResponse
+	Item   sankhya.RomaneioItem
+}
+
+var historyExportColumns = []export.Column[sankhya.HistoryPageItem]{
+	{Key: "tipo", Header: "Tipo", Kind: export.Text, Value: func(i sankhya.HistoryPageItem) any { return i.Tipo }},
+	{Key: "data", Header: "Data", Kind: export.Date, Value: func(i sankhya.HistoryPageItem) any { return i.DatGer }},
+	{Key: "hora", Header: "Hora", Kind: export.Text, Value: func(i sankhya.HistoryPageItem) any { return i.Hora }},
+	{Key: "idOperacao", Header: "Operação", Kind: export.Integer, Value: func(i sankhya.HistoryPageItem) any { return i.IdOperacao }},
+	{Key: "seqIte", Header: "Item", Kind: export.Integer, Value: func(i sankhya.HistoryPageItem) any { return i.SeqIte }},
+	{Key: "codArm", Header: "Armazém", Kind: export.Integer, Value: func(i sankhya.HistoryPageItem) any { return i.CodArm }},
+	{Key: "seqEnd", Header: "Endereço", Kind: export.Integer, Value: func(i sankhya.HistoryPageItem) any { return i.SeqEnd }},
+	{Key: "armDes", Header: "Armazém Destino", Kind: export.Text, Value: func(i sankhya.HistoryPageItem) any { return i.ArmDes }},
+	{Key: "endDes", Header: "Endereço Destino", Kind: export.Text, Value: func(i sankhya.HistoryPageItem) any { return i.EndDes }},
+	{Key: "codProd", Header: "Produto", Kind: export.Integer, Value: func(i sankhya.HistoryPageItem) any { return i.CodProd }},
+	{Key: "descrProd", Header: "Descrição", Kind: export.Text, Value: func(i sankhya.HistoryPageItem) any { return i.DescrProd }},
+	{Key: "marca", Header: "Marca", Kind: export.Text, Value: func(i sankhya.HistoryPageItem) any { return i.Marca }},
+	{Key: "derivacao", Header: "Derivação", Kind: export.Text, Value: func(i sankhya.HistoryPageItem) any { return i.Derivacao }},
+	{Key: "qtdProd", Header: "Quantidade", Kind: export.Decimal, Value: func(i sankhya.HistoryPageItem) any { return i.QtdProd }},
+	{Key: "quantAnt", Header: "Qtd. Anterior", Kind: export.Decimal, Value: func(i sankhya.HistoryPageItem) any { return i.QuantAnt }},
+	{Key: "qtdAtual", Header: "Qtd. Atual", Kind: export.Decimal, Value: func(i sankhya.HistoryPageItem) any { return i.QtdAtual }},
+	{Key: "codUsu", Header: "Cód. Usuário", Kind: export.Integer, Value: func(i sankhya.HistoryPageItem) any { return i.CodUsu }},
+	{Key: "nomeUsu", Header: "Usuário", Kind: export.Text, Value: func(i sankhya.HistoryPageItem) any { return i.NomeUsu }},
+}
+
+var stockExportColumns = []export.Column[sankhya.SearchItemResult]{
+	{Key: "seqEnd", Header: "Endereço", Kind: export.Integer, Value: func(i sankhya.SearchItemResult) any { return i.SeqEnd }},
+	{Key: "codRua", Header: "Rua", Kind: export.Text, Value: func(i sankhya.SearchItemResult) any { return i.CodRua }},
+	{Key: "codPrd", Header: "Prédio", Kind: export.Integer, Value: func(i sankhya.SearchItemResult) any { return i.CodPrd }},
+	{Key: "codApt", Header: "Apartamento", Kind: export.Text, Value: func(i sankhya.SearchItemResult) any { return i.CodApt }},
+	{Key: "codProd", Header: "Produto", Kind: export.Integer, Value: func(i sankhya.SearchItemResult) any { return i.CodProd }},
+	{Key: "descrProd", Header: "Descrição", Kind: export.Text, Value: func(i sankhya.SearchItemResult) any { return i.DescrProd }},
+	{Key: "marca", Header: "Marca", Kind: export.Text, Value: func(i sankhya.SearchItemResult) any { return i.Marca }},
+	{Key: "derivacao", Header: "Derivação", Kind: export.Text, Value: func(i sankhya.SearchItemResult) any { return i.Derivacao }},
+	{Key: "datVal", Header: "Validade", Kind: export.Date, Value: func(i sankhya.SearchItemResult) any { return i.DatVal }},
+	{Key: "qtdPro", Header: "Quantidade", Kind: export.Decimal, Value: func(i sankhya.SearchItemResult) any { return i.QtdPro }},
+	{Key: "qtdCompleta", Header: "Qtd. Completa", Kind: export.Text, Value: func(i sankhya.SearchItemResult) any { return i.QtdCompleta }},
+	{Key: "endPic", Header: "Picking", Kind: export.Text, Value: func(i sankhya.SearchItemResult) any { return i.EndPic }},
+}
+
+var romaneioExportColumns = []export.Column[romaneioExportRow]{
+	{Key: "fechamento", Header: "Fechamento", Kind: export.Integer, Value: func(r romaneioExportRow) any { return r.Header.Fechamento }},
+	{Key: "data", Header: "Data", Kind: export.Date, Value: func(r romaneioExportRow) any { return r.Header.Data }},
+	{Key: "motorista", Header: "Motorista", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Header.Motorista }},
+	{Key: "placa", Header: "Placa", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Header.Placa }},
+	{Key: "veiculo", Header: "Veículo", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Header.Veiculo }},
+	{Key: "status_conf", Header: "Status Conferência", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Header.StatusConferencia }},
+	{Key: "tipo", Header: "Tipo", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Item.Tipo }},
+	{Key: "codigo_produto", Header: "Produto", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Item.CodigoProduto }},
+	{Key: "descricao", Header: "Descrição", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Item.Descricao }},
+	{Key: "unidade", Header: "Unidade", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Item.Unidade }},
+	{Key: "referencia", Header: "Referência", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Item.Referencia }},
+	{Key: "quantidade", Header: "Quantidade", Kind: export.Decimal, Value: func(r romaneioExportRow) any { return r.Item.Quantidade }},
+	{Key: "qtd_embarcada", Header: "Qtd. Conferida", Kind: export.Decimal, Value: func(r romaneioExportRow) any { return r.Item.QtdEmbarcada }},
+	{Key: "peso_bruto", Header: "Peso Bruto", Kind: export.Decimal, Value: func(r romaneioExportRow) any { return r.Item.PesoBruto }},
+	{Key: "conferido", Header: "Conferido", Kind: export.Text, Value: func(r romaneioExportRow) any { return r.Item.Conferido }},
+}
+
+// authenticate valida JWT + sessão e devolve o CODUSU
+func (h *ExportHandler) authenticate(w http.ResponseWriter, r *http.Request) (int, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return 0, false
+	}
+
+	token := getTokenFromHeader(r)
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return 0, false
+	}
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return 0, false
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return 0, false
+	}
+	return codUsu, true
+}
+
+// newExportTable valida formato/colunas e escreve os headers HTTP do download.
+// Depois dela a resposta já começou: erros só podem abortar a conexão (abortExport).
+func newExportTable[T any](h *ExportHandler, w http.ResponseWriter, r *http.Request, opts exportOptions, all []export.Column[T], name string) (*export.Table[T], bool) {
+	format, err := export.ParseFormat(opts.Format)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), err)
+		return nil, false
+	}
+	cols, err := export.SelectColumns(all, opts.Columns)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), err)
+		return nil, false
+	}
+
+	filename := fmt.Sprintf("%s_%s.%s", name, time.Now().Format("20060102_150405"), format.Extension())
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Cache-Control", "no-store")
+
+	table, err := export.NewTable(format, w, name, cols)
+	if err != nil {
+		abortExport(err, name)
+	}
+	return table, true
+}
+
+// abortExport interrompe a resposta para o cliente não receber um arquivo truncado como se estivesse completo
+func abortExport(err error, name string) {
+	slog.Error("Exportação interrompida", "export", name, "error", err)
+	panic(http.ErrAbortHandler)
+}
+
+func flushExport[T any](w http.ResponseWriter, table *export.Table[T], name string) {
+	if err := table.Flush(); err != nil {
+		abortExport(err, name)
+	}
+	if err := http.NewResponseController(w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		abortExport(err, name)
+	}
+}
+
+func (h *ExportHandler) HandleExportHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var input exportHistoryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.DtIni == "" || input.DtFim == "" {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "dtIni e dtFim são obrigatórios", nil)
+		return
+	}
+
+	perms, err := h.Client.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar permissões", err)
+		return
+	}
+	if input.CodArm > 0 && !perms.CanAccessWarehouse(input.CodArm) {
+		RespondWarehouseForbidden(w, r, fmt.Errorf("%w: %d", sankhya.ErrWarehouseNotAllowed, input.CodArm))
+		return
+	}
+
+	filter := input.HistoryFilter
+	filter.CodUsu = perms.HistoryUserFilter(filter.CodUsu)
+	filter.Armazens = perms.Armazens()
+	filter.Limit = exportPageSize
+
+	// Primeira página antes de abrir o download, para erros de filtro/ERP ainda virarem JSON
+	page, err := h.Client.SearchHistory(ctx, filter)
+	if err != nil {
+		if errors.Is(err, sankhya.ErrHistoryCursorInvalido) || errors.Is(err, sankhya.ErrHistoryFiltroInvalido) {
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar histórico", err)
+		return
+	}
+
+	table, ok := newExportTable(h, w, r, input.exportOptions, historyExportColumns, "historico")
+	if !ok {
+		return
+	}
+
+	slog.Info("Exportação de Histórico", "user", codUsu, "filtroUsu", filter.CodUsu, "dtIni", filter.DtIni, "dtFim", filter.DtFim, "format", input.Format)
+
+	total := 0
+	for {
+		for _, item := range page.Items {
+			if err := table.Write(item); err != nil {
+				abortExport(err, "historico")
+			}
+		}
+		total += len(page.Items)
+		flushExport(w, table, "historico")
+
+		if !page.HasMore {
+			break
+		}
+		filter.Cursor = page.NextCursor
+		if page, err = h.Client.SearchHistory(ctx, filter); err != nil {
+			abortExport(err, "historico")
+		}
+	}
+
+	if err := table.Close(); err != nil {
+		abortExport(err, "historico")
+	}
+	slog.Info("Exportação de Histórico concluída", "user", codUsu, "linhas", total)
+}
+
+func (h *ExportHandler) HandleExportStock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var input exportStockInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.CodArm == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "codArm é obrigatório", nil)
+		return
+	}
+
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
+	query := sankhya.SearchItemsQuery{
+		CodArm: input.CodArm,
+		Filtro: input.Filtro,
+		Limit:  exportPageSize,
+		Sort:   input.Sort,
+		Dir:    input.Dir,
+	}
+
+	page, err := h.Client.SearchItemsPaged(ctx, query)
+	if err != nil {
+		if errors.Is(err, sankhya.ErrSearchSortInvalido) {
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar itens", err)
+		return
+	}
+
+	table, ok := newExportTable(h, w, r, input.exportOptions, stockExportColumns, fmt.Sprintf("estoque_arm%d", input.CodArm))
+	if !ok {
+		return
+	}
+
+	slog.Info("Exportação de Estoque", "user", codUsu, "codArm", input.CodArm, "linhas", page.Total, "format", input.Format)
+
+	for {
+		for _, item := range page.Items {
+			if err := table.Write(item); err != nil {
+				abortExport(err, "estoque")
+			}
+		}
+		flushExport(w, table, "estoque")
+
+		if !page.HasMore || len(page.Items) == 0 {
+			break
+		}
+		query.Offset += len(page.Items)
+		if page, err = h.Client.SearchItemsPaged(ctx, query); err != nil {
+			abortExport(err, "estoque")
+		}
+	}
+
+	if err := table.Close(); err != nil {
+		abortExport(err, "estoque")
+	}
+}
+
+func (h *ExportHandler) HandleExportRomaneio(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 40*time.Second)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var input exportRomaneioInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.NumeroFechamento == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "Número do fechamento é obrigatório", nil)
+		return
+	}
+
+	detalhes, err := h.Client.GetRomaneioDetalhes(ctx, input.NumeroFechamento)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar detalhes do romaneio", err)
+		return
+	}
+
+	table, ok := newExportTable(h, w, r, input.exportOptions, romaneioExportColumns, fmt.Sprintf("romaneio_%d", input.NumeroFechamento))
+	if !ok {
+		return
+	}
+
+	slog.Info("Exportação de Romaneio", "user", codUsu, "fechamento", input.NumeroFechamento, "produtos", len(detalhes.Produtos), "format", input.Format)
+
+	for _, item := range detalhes.Produtos {
+		if err := table.Write(romaneioExportRow{Header: detalhes, Item: item}); err != nil {
+			abortExport(err, "romaneio")
+		}
+	}
+	if err := table.Close(); err != nil {
+		abortExport(err, "romaneio")
+	}
+}
@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
+	"zenith-go/internal/conference"
 	"zenith-go/internal/notification"
+	"zenith-go/internal/sankhya"
 )
 
 // ErrorMeta estrutura auxiliar para passar contexto do usuário para o erro
@@ -100,6 +104,110 @@ func RespondError(w http.ResponseWriter, r *http.Request, notifier *notification
 	})
 }
 
+// Códigos de erro estáveis para o app tratar sem depender do texto da mensagem
+const (
+	ErrCodeWarehouseForbidden = "ARMAZEM_NAO_AUTORIZADO"
+	ErrCodeCodigoAmbiguo      = "CODIGO_AMBIGUO" // Leitura com mais de um produto/unidade (resolve-barcode e conferência)
+	ErrCodeConfCodigoAmbiguo  = ErrCodeCodigoAmbiguo
+	ErrCodeConfJaConferido    = "ITEM_JA_CONFERIDO"
+	ErrCodeConfNumRegInvalido = "NUM_REG_NAO_CORRESPONDE"
+	ErrCodeConfPendente       = "CONFERENCIA_PENDENTE"
+	ErrCodeConfEmUso          = "CONFERENCIA_EM_USO"
+	ErrCodeConfFinalizada     = "CONFERENCIA_FINALIZADA"
+	ErrCodeConfNaoConferido   = "ITEM_NAO_CONFERIDO"
+)
+
+// RespondWarehouseForbidden responde 403 quando o armazém está fora de AD_PERMEND do usuário
+func RespondWarehouseForbidden(w http.ResponseWriter, r *http.Request, err error) {
+	slog.Warn("Armazém não autorizado", "error", err.Error(), "path", r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":   "Usuário sem acesso a este armazém",
+		"code":    ErrCodeWarehouseForbidden,
+		"details": err.Error(),
+	})
+}
+
+// RespondConferenceConflict responde 409 com as linhas candidatas, para o coletor escolher o num_reg
+func RespondConferenceConflict(w http.ResponseWriter, r *http.Request, code string, err *sankhya.ConferenceMatchError) {
+	slog.Warn("Leitura de conferência não resolvida", "code", code, "error", err.Error(), "candidatos", len(err.Candidatos), "path", r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":      err.Error(),
+		"code":       code,
+		"candidatos": err.Candidatos,
+	})
+}
+
+// RespondBarcodeConflict responde 409 com os produtos/unidades do código, para o coletor escolher
+func RespondBarcodeConflict(w http.ResponseWriter, r *http.Request, err *sankhya.BarcodeMatchError) {
+	slog.Warn("Código de barras ambíguo", "error", err.Error(), "candidatos", len(err.Candidatos), "path", r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":      err.Error(),
+		"code":       ErrCodeCodigoAmbiguo,
+		"candidatos": err.Candidatos,
+	})
+}
+
+// RespondConferenceBlocked responde 409 com o andamento quando a finalização é bloqueada por divergências
+func RespondConferenceBlocked(w http.ResponseWriter, r *http.Request, err *sankhya.ConferenceBlockedError) {
+	slog.Warn("Finalização de conferência bloqueada", "error", err.Error(), "path", r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":     err.Error(),
+		"code":      ErrCodeConfPendente,
+		"progresso": err.Progress,
+	})
+}
+
+// RespondConferenceLocked responde 409 com o dono atual quando outro operador está com a conferência
+func RespondConferenceLocked(w http.ResponseWriter, r *http.Request, err *conference.LockError) {
+	slog.Warn("Conferência em uso por outro operador", "nuUnico", err.Lease.NuUnico, "dono", err.Lease.CodUsu, "path", r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": err.Error(),
+		"code":  ErrCodeConfEmUso,
+		"posse": err.Lease,
+	})
+}
+
+// RespondConferenceState responde 409 quando o estado da conferência ou da linha não permite a operação
+func RespondConferenceState(w http.ResponseWriter, r *http.Request, code string, err error) {
+	slog.Warn("Operação não permitida no estado da conferência", "code", code, "error", err.Error(), "path", r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+		"code":  code,
+	})
+}
+
+// authorizeWarehouses valida os armazéns para leituras; responde 403/500 e retorna false se negado
+func authorizeWarehouses(ctx context.Context, w http.ResponseWriter, r *http.Request, client *sankhya.Client, notifier *notification.EmailService, codUsu int, codArms ...int) (*sankhya.UserPermissions, bool) {
+	perms, err := client.AuthorizeWarehouses(ctx, codUsu, codArms...)
+	if err != nil {
+		if errors.Is(err, sankhya.ErrWarehouseNotAllowed) {
+			RespondWarehouseForbidden(w, r, err)
+		} else {
+			RespondError(w, r, notifier, http.StatusInternalServerError, "Erro ao verificar permissões", err)
+		}
+		return nil, false
+	}
+	return perms, true
+}
+
 // maskID oculta o meio da string (Ex: "ABCDEF123456" -> "ABCD...3456")
 func maskID(id string) string {
 	const visibleChars = 4
@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"net/http"
+	"strings"
+	"time"
+	"zenith-go/internal/auth"
+	"zenith-go/internal/config"
+	"zenith-go/internal/label"
+	"zenith-go/internal/notification"
+	"zenith-go/internal/printing"
+	"zenith-go/internal/sankhya"
+)
+
+// Máximo de endereços por pedido de etiqueta (cada um vira um ^XA...^XZ)
+const maxLabelsPerRequest = 200
+
+type LabelHandler struct {
+	Client    *sankhya.Client
+	Config    *config.Config
+	Session   *auth.SessionManager
+	Notifier  *notification.EmailService
+	Templates *label.Templates
+	Queue     *printing.Queue
+}
+
+// labelRequest é o corpo dos endpoints de etiqueta; Impressora só é usada em /label/print
+type labelRequest struct {
+	sankhya.LabelInput
+	Impressora string `json:"impressora"` // Vazio = impressora padrão do armazém
+}
+
+// errLabelSemProduto indica etiqueta de palete pedida para endereço vazio
+var errLabelSemProduto = errors.New("endereço sem produto")
+
+func (h *LabelHandler) authenticate(w http.ResponseWriter, r *http.Request) (int, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return 0, false
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return 0, false
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return 0, false
+	}
+	return codUsu, true
+}
+
+// decodeLabelInput valida o corpo e o acesso ao armazém. Responde o erro e retorna false se inválido.
+func (h *LabelHandler) decodeLabelInput(ctx context.Context, w http.ResponseWriter, r *http.Request, codUsu int) (labelRequest, label.Kind, bool) {
+	var input labelRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return input, "", false
+	}
+
+	kind, err := label.ParseKind(input.Tipo)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "tipo deve ser 'endereco' ou 'palete'", err)
+		return input, "", false
+	}
+	if input.CodArm == 0 || len(input.Sequencias) == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "codArm e sequencias são obrigatórios", nil)
+		return input, "", false
+	}
+	if len(input.Sequencias) > maxLabelsPerRequest {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, fmt.Sprintf("Máximo de %d etiquetas por pedido", maxLabelsPerRequest), nil)
+		return input, "", false
+	}
+	if input.Copias < 0 || input.Copias > 99 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "copias deve estar entre 1 e 99", nil)
+		return input, "", false
+	}
+
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return input, "", false
+	}
+	return input, kind, true
+}
+
+// buildLabels busca os endereços e renderiza uma etiqueta por endereço, na ordem pedida
+func (h *LabelHandler) buildLabels(ctx context.Context, input sankhya.LabelInput, kind label.Kind) ([]string, error) {
+	enderecos, err := h.Client.GetLabelAddresses(ctx, input.CodArm, input.Sequencias)
+	if err != nil {
+		return nil, err
+	}
+
+	impressao := time.Now().Format("02/01/2006 15:04")
+	labels := make([]string, 0, len(enderecos))
+	for _, e := range enderecos {
+		var data any
+		switch kind {
+		case label.KindEndereco:
+			data = label.AddressLabel{
+				CodArm: e.CodArm, DesArm: e.DesArm, SeqEnd: e.SeqEnd,
+				CodRua: e.CodRua, CodPrd: e.CodPrd, CodApt: e.CodApt,
+				Picking: e.EndPic == "S", CodProd: e.CodProd, DescrProd: e.DescrProd,
+				Copias: input.Copias,
+			}
+		case label.KindPalete:
+			if e.CodProd == 0 {
+				return nil, fmt.Errorf("%w: %d", errLabelSemProduto, e.SeqEnd)
+			}
+			qtd := e.QtdPro
+			if input.Quantidade != nil {
+				qtd = *input.Quantidade
+			}
+			data = label.PalletLabel{
+				CodArm: e.CodArm, DesArm: e.DesArm, SeqEnd: e.SeqEnd,
+				CodRua: e.CodRua, CodPrd: e.CodPrd, CodApt: e.CodApt,
+				CodProd: e.CodProd, DescrProd: e.DescrProd, Marca: e.Marca, Derivacao: e.Derivacao,
+				DatVal: e.DatVal, Quantidade: qtd, CodVol: e.CodVol,
+				Impressao: impressao, Copias: input.Copias,
+			}
+		}
+
+		zpl, err := h.Templates.Render(kind, input.CodArm, data)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, zpl)
+	}
+	return labels, nil
+}
+
+// respondLabelError traduz os erros de montagem da etiqueta em status HTTP
+func (h *LabelHandler) respondLabelError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, sankhya.ErrItemNotFound):
+		RespondError(w, r, h.Notifier, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, errLabelSemProduto):
+		RespondError(w, r, h.Notifier, http.StatusUnprocessableEntity, err.Error(), err)
+	case errors.Is(err, label.ErrTemplateInvalido):
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Template de etiqueta inválido", err)
+	default:
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao gerar etiqueta", err)
+	}
+}
+
+// HandleLabelZPL devolve o ZPL das etiquetas (uma por endereço, concatenadas)
+func (h *LabelHandler) HandleLabelZPL(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	input, kind, ok := h.decodeLabelInput(ctx, w, r, codUsu)
+	if !ok {
+		return
+	}
+
+	labels, err := h.buildLabels(ctx, input.LabelInput, kind)
+	if err != nil {
+		h.respondLabelError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="etiqueta_%s_%d.zpl"`, kind, input.CodArm))
+	w.Write([]byte(strings.Join(labels, "")))
+}
+
+// HandleLabelPreview renderiza a etiqueta do primeiro endereço em PNG (1 pixel = 1 ponto)
+func (h *LabelHandler) HandleLabelPreview(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	input, kind, ok := h.decodeLabelInput(ctx, w, r, codUsu)
+	if !ok {
+		return
+	}
+	input.Sequencias = input.Sequencias[:1]
+
+	labels, err := h.buildLabels(ctx, input.LabelInput, kind)
+	if err != nil {
+		h.respondLabelError(w, r, err)
+		return
+	}
+
+	img, err := label.Preview(labels[0])
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnprocessableEntity, "Não foi possível renderizar a prévia: "+err.Error(), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	png.Encode(w, img)
+}
+
+// HandleLabelPrint gera as etiquetas e enfileira o envio para a impressora de rede.
+// Responde 202 com o job; o coletor acompanha por /apiv1/label/job.
+func (h *LabelHandler) HandleLabelPrint(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	input, kind, ok := h.decodeLabelInput(ctx, w, r, codUsu)
+	if !ok {
+		return
+	}
+
+	var printer config.Printer
+	if input.Impressora != "" {
+		printer, ok = h.Config.Printers.Find(input.Impressora)
+		if !ok || printer.CodArm != input.CodArm {
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, fmt.Sprintf("Impressora '%s' não cadastrada no armazém %d", input.Impressora, input.CodArm), nil)
+			return
+		}
+	} else if printer, ok = h.Config.Printers.Default(input.CodArm); !ok {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, fmt.Sprintf("Armazém %d sem impressora padrão: informe a impressora", input.CodArm), nil)
+		return
+	}
+
+	labels, err := h.buildLabels(ctx, input.LabelInput, kind)
+	if err != nil {
+		h.respondLabelError(w, r, err)
+		return
+	}
+
+	job, err := h.Queue.Enqueue(ctx, printing.Job{
+		Impressora: printer.ID,
+		Endereco:   printer.Endereco,
+		CodArm:     input.CodArm,
+		Tipo:       string(kind),
+		Etiquetas:  len(labels),
+		CodUsu:     codUsu,
+	}, strings.Join(labels, ""))
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Fila de impressão indisponível", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// HandleLabelJobStatus consulta um job de impressão (do próprio usuário ou de armazém liberado)
+func (h *LabelHandler) HandleLabelJobStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.ID == "" {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "id do job é obrigatório", err)
+		return
+	}
+
+	job, err := h.Queue.Get(ctx, input.ID)
+	if err != nil {
+		if errors.Is(err, printing.ErrJobNaoEncontrado) {
+			RespondError(w, r, h.Notifier, http.StatusNotFound, "Job não encontrado ou expirado", err)
+			return
+		}
+		RespondError(w, r, h.Notifier, http.StatusServiceUnavailable, "Fila de impressão indisponível", err)
+		return
+	}
+
+	if job.CodUsu != codUsu {
+		if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, job.CodArm); !ok {
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// HandleListPrinters lista as impressoras cadastradas para o armazém
+func (h *LabelHandler) HandleListPrinters(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		CodArm int `json:"codArm"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.CodArm == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "codArm é obrigatório", err)
+		return
+	}
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Config.Printers.ForWarehouse(input.CodArm))
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 	"zenith-go/internal/auth"
@@ -24,6 +26,11 @@ type ProductHandler struct {
 type searchItemsInput struct {
 	CodArm int    `json:"codArm"`
 	Filtro string `json:"filtro"`
+	// Paginação (opcional): sem limit/offset/sort a resposta continua sendo o array completo
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Sort   string `json:"sort"`
+	Dir    string `json:"dir"`
 }
 
 type getItemDetailsInput struct {
@@ -31,6 +38,22 @@ type getItemDetailsInput struct {
 	Sequencia string `json:"sequencia"`
 }
 
+type getItemDetailsBulkInput struct {
+	Itens []getItemDetailsInput `json:"itens"`
+}
+
+// itemDetailsBulkEntry é o resultado de um endereço do lote; Item só vem quando Encontrado
+type itemDetailsBulkEntry struct {
+	CodArm     int                 `json:"codArm"`
+	Sequencia  string              `json:"sequencia"`
+	Encontrado bool                `json:"encontrado"`
+	Item       *sankhya.ItemDetail `json:"item,omitempty"`
+	Erro       string              `json:"erro,omitempty"`
+}
+
+// Máximo de endereços por chamada do lote de detalhes
+const maxItemDetailsBulk = 500
+
 type getPickingLocationsInput struct {
 	CodArm    int `json:"codarm"`
 	CodProd   int `json:"codprod"`
@@ -84,7 +107,34 @@ func (h *ProductHandler) HandleSearchItems(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	slog.Info("Busca de produtos", "user", codUsu, "codArm", input.CodArm, "filtro", input.Filtro)
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
+	slog.Info("Busca de produtos", "user", codUsu, "codArm", input.CodArm, "filtro", input.Filtro, "limit", input.Limit, "offset", input.Offset, "sort", input.Sort)
+
+	if input.Limit > 0 || input.Offset > 0 || input.Sort != "" {
+		page, err := h.Client.SearchItemsPaged(ctx, sankhya.SearchItemsQuery{
+			CodArm: input.CodArm,
+			Filtro: input.Filtro,
+			Limit:  input.Limit,
+			Offset: input.Offset,
+			Sort:   input.Sort,
+			Dir:    input.Dir,
+		})
+		if err != nil {
+			if errors.Is(err, sankhya.ErrSearchSortInvalido) {
+				RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), err)
+				return
+			}
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro na busca de produtos", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+		return
+	}
 
 	rows, err := h.Client.SearchItems(ctx, input.CodArm, input.Filtro)
 	if err != nil {
@@ -106,7 +156,8 @@ func (h *ProductHandler) HandleGetItemDetails(w http.ResponseWriter, r *http.Req
 	}
 
 	token := getTokenFromHeaderProduct(r)
-	if _, _, err := auth.ValidateToken(token, h.Config.JwtSecret); err != nil {
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
 		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
 		return
 	}
@@ -121,6 +172,10 @@ func (h *ProductHandler) HandleGetItemDetails(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
 	item, err := h.Client.GetItemDetails(ctx, input.CodArm, input.Sequencia)
 	if err != nil {
 		if errors.Is(err, sankhya.ErrItemNotFound) {
@@ -135,6 +190,98 @@ func (h *ProductHandler) HandleGetItemDetails(w http.ResponseWriter, r *http.Req
 	json.NewEncoder(w).Encode(item)
 }
 
+// HandleGetItemDetailsBulk busca vários endereços em uma única query. A resposta é indexada
+// por "codArm:sequencia"; endereços inexistentes ou inválidos vêm marcados, sem derrubar o lote.
+func (h *ProductHandler) HandleGetItemDetailsBulk(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input getItemDetailsBulkInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if len(input.Itens) == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "itens é obrigatório", nil)
+		return
+	}
+	if len(input.Itens) > maxItemDetailsBulk {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, fmt.Sprintf("Máximo de %d endereços por chamada", maxItemDetailsBulk), nil)
+		return
+	}
+
+	codArms := make([]int, 0, len(input.Itens))
+	for _, it := range input.Itens {
+		codArms = append(codArms, it.CodArm)
+	}
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, codArms...); !ok {
+		return
+	}
+
+	entries := make(map[string]*itemDetailsBulkEntry, len(input.Itens))
+	var enderecos []sankhya.ItemAddress
+	for _, it := range input.Itens {
+		seq := strings.TrimSpace(it.Sequencia)
+		key := fmt.Sprintf("%d:%s", it.CodArm, seq)
+		if _, dup := entries[key]; dup {
+			continue
+		}
+		entry := &itemDetailsBulkEntry{CodArm: it.CodArm, Sequencia: seq}
+		entries[key] = entry
+
+		seqEnd, err := strconv.Atoi(seq)
+		if err != nil || seqEnd <= 0 {
+			entry.Erro = "sequência inválida"
+			continue
+		}
+		enderecos = append(enderecos, sankhya.ItemAddress{CodArm: it.CodArm, SeqEnd: seqEnd})
+	}
+
+	itens, err := h.Client.GetItemDetailsBulk(ctx, enderecos)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar detalhes", err)
+		return
+	}
+
+	encontrados := 0
+	for _, entry := range entries {
+		if entry.Erro != "" {
+			continue
+		}
+		seqEnd, _ := strconv.Atoi(entry.Sequencia)
+		if item, ok := itens[sankhya.ItemAddress{CodArm: entry.CodArm, SeqEnd: seqEnd}]; ok {
+			entry.Encontrado = true
+			entry.Item = item
+			encontrados++
+		} else {
+			entry.Erro = sankhya.ErrItemNotFound.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"itens":          entries,
+		"encontrados":    encontrados,
+		"naoEncontrados": len(entries) - encontrados,
+	})
+}
+
 func (h *ProductHandler) HandleGetPickingLocations(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
@@ -145,7 +292,8 @@ func (h *ProductHandler) HandleGetPickingLocations(w http.ResponseWriter, r *htt
 	}
 
 	token := getTokenFromHeaderProduct(r)
-	if _, _, err := auth.ValidateToken(token, h.Config.JwtSecret); err != nil {
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
 		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
 		return
 	}
@@ -160,6 +308,10 @@ func (h *ProductHandler) HandleGetPickingLocations(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
 	locations, err := h.Client.GetPickingLocations(ctx, input.CodArm, input.CodProd, input.Sequencia)
 	if err != nil {
 		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar picking", err)
@@ -196,9 +348,18 @@ func (h *ProductHandler) HandleGetHistory(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	slog.Info("Consulta de Histórico", "user", codUsu, "dtIni", input.DtIni, "dtFim", input.DtFim)
+	perms, err := h.Client.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar permissões", err)
+		return
+	}
+
+	// O filtro de usuário vem das claims do JWT, não do body
+	filtroUsu := perms.HistoryUserFilter(input.CodUsu)
 
-	history, err := h.Client.GetHistory(ctx, input.DtIni, input.DtFim, input.CodUsu)
+	slog.Info("Consulta de Histórico", "user", codUsu, "filtroUsu", filtroUsu, "dtIni", input.DtIni, "dtFim", input.DtFim)
+
+	history, err := h.Client.GetHistory(ctx, input.DtIni, input.DtFim, filtroUsu, perms.Armazens())
 	if err != nil {
 		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar histórico", err)
 		return
@@ -206,4 +367,483 @@ func (h *ProductHandler) HandleGetHistory(w http.ResponseWriter, r *http.Request
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(history)
-}
\ No newline at end of file
+}
+
+// HandleResolveBarcode resolve um código lido (EAN/DUN ou etiqueta de endereço)
+func (h *ProductHandler) HandleResolveBarcode(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.BarcodeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	if strings.TrimSpace(input.Codigo) == "" {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "O campo 'codigo' é obrigatório", nil)
+		return
+	}
+
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
+	slog.Info("Leitura de código de barras", "user", codUsu, "codArm", input.CodArm, "codigo", input.Codigo)
+
+	result, err := h.Client.ResolveBarcode(ctx, input)
+	if err != nil {
+		var matchErr *sankhya.BarcodeMatchError
+		if errors.As(err, &matchErr) {
+			RespondBarcodeConflict(w, r, matchErr)
+		} else if errors.Is(err, sankhya.ErrItemNotFound) {
+			RespondError(w, r, h.Notifier, http.StatusNotFound, "Código não encontrado", nil)
+		} else {
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao resolver código de barras", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *ProductHandler) HandleSearchHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.HistoryFilter
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.DtIni == "" || input.DtFim == "" {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "dtIni e dtFim são obrigatórios", nil)
+		return
+	}
+
+	perms, err := h.Client.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar permissões", err)
+		return
+	}
+	if input.CodArm > 0 && !perms.CanAccessWarehouse(input.CodArm) {
+		RespondWarehouseForbidden(w, r, fmt.Errorf("%w: %d", sankhya.ErrWarehouseNotAllowed, input.CodArm))
+		return
+	}
+
+	input.CodUsu = perms.HistoryUserFilter(input.CodUsu)
+	input.Armazens = perms.Armazens()
+
+	slog.Info("Consulta de Histórico Paginada", "user", codUsu, "filtroUsu", input.CodUsu, "tipo", input.Tipo, "codArm", input.CodArm, "cursor", input.Cursor != "")
+
+	page, err := h.Client.SearchHistory(ctx, input)
+	if err != nil {
+		if errors.Is(err, sankhya.ErrHistoryCursorInvalido) || errors.Is(err, sankhya.ErrHistoryFiltroInvalido) {
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar histórico", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *ProductHandler) HandleGetOperationDetail(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.OperationDetailInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.Tipo == "" || input.IdOperacao <= 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "tipo e idOperacao são obrigatórios", nil)
+		return
+	}
+
+	perms, err := h.Client.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar permissões", err)
+		return
+	}
+
+	detail, err := h.Client.GetOperationDetail(ctx, input.Tipo, input.IdOperacao)
+	if err != nil {
+		switch {
+		case errors.Is(err, sankhya.ErrOperacaoNaoEncontrada):
+			RespondError(w, r, h.Notifier, http.StatusNotFound, "Operação não encontrada", nil)
+		case errors.Is(err, sankhya.ErrHistoryFiltroInvalido):
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), err)
+		default:
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar detalhe da operação", err)
+		}
+		return
+	}
+
+	// Mesmas regras do histórico (HISTGER e AD_PERMEND). Fora do escopo responde igual a
+	// "não encontrada", para não revelar se o id existe
+	if !operationVisible(perms, detail) {
+		slog.Warn("Detalhe de operação fora do escopo", "user", codUsu, "tipo", detail.Tipo, "idOperacao", detail.IdOperacao, "dono", detail.CodUsu)
+		RespondError(w, r, h.Notifier, http.StatusNotFound, "Operação não encontrada", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detail)
+}
+
+func (h *ProductHandler) HandleGetProductStock(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.ProductStockInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.CodProd <= 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "codProd é obrigatório", nil)
+		return
+	}
+
+	perms, err := h.Client.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar permissões", err)
+		return
+	}
+
+	slog.Info("Visão de estoque do produto", "user", codUsu, "codProd", input.CodProd, "armazens", perms.ListaCodigos)
+
+	overview, err := h.Client.GetProductStock(ctx, input.CodProd, perms.Armazens())
+	if err != nil {
+		if errors.Is(err, sankhya.ErrItemNotFound) {
+			RespondError(w, r, h.Notifier, http.StatusNotFound, "Produto não encontrado", err)
+			return
+		}
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar estoque do produto", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overview)
+}
+
+func (h *ProductHandler) HandleGetExpiryReport(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.ExpiryReportFilter
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	perms, err := h.Client.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar permissões", err)
+		return
+	}
+	if input.CodArm > 0 && !perms.CanAccessWarehouse(input.CodArm) {
+		RespondWarehouseForbidden(w, r, fmt.Errorf("%w: %d", sankhya.ErrWarehouseNotAllowed, input.CodArm))
+		return
+	}
+
+	slog.Info("Relatório de validade", "user", codUsu, "dias", input.Dias, "codArm", input.CodArm, "codProd", input.CodProd, "marca", input.Marca)
+
+	report, err := h.Client.GetExpiryReport(ctx, perms.Armazens(), input)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao gerar relatório de validade", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *ProductHandler) HandleGetReplenishment(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.ReplenishmentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.CodArm == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "codArm é obrigatório", nil)
+		return
+	}
+
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
+	slog.Info("Sugestões de reposição", "user", codUsu, "codArm", input.CodArm, "codProd", input.CodProd)
+
+	report, err := h.Client.GetReplenishmentSuggestions(ctx, input.CodArm, input.CodProd)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao gerar sugestões de reposição", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *ProductHandler) HandleListEmptyAddresses(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.EmptyAddressInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.CodArm == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "codArm é obrigatório", nil)
+		return
+	}
+
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
+	enderecos, err := h.Client.ListEmptyAddresses(ctx, input)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar endereços vazios", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enderecos)
+}
+
+func (h *ProductHandler) HandleGetPutawaySuggestions(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.PutawayInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.CodArm == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "codArm é obrigatório", nil)
+		return
+	}
+
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
+	slog.Info("Sugestão de armazenagem", "user", codUsu, "codArm", input.CodArm, "sequencia", input.Sequencia, "codProd", input.CodProd)
+
+	sug, err := h.Client.GetPutawaySuggestions(ctx, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, sankhya.ErrPutawaySemProduto):
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, sankhya.ErrItemNotFound):
+			RespondError(w, r, h.Notifier, http.StatusNotFound, "Endereço de origem não encontrado", err)
+		default:
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao gerar sugestões de armazenagem", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sug)
+}
+
+func (h *ProductHandler) HandleGetOccupancyMap(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeaderProduct(r)
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.OccupancyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.CodArm == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "codArm é obrigatório", nil)
+		return
+	}
+
+	if _, ok := authorizeWarehouses(ctx, w, r, h.Client, h.Notifier, codUsu, input.CodArm); !ok {
+		return
+	}
+
+	mapa, err := h.Client.GetOccupancyMap(ctx, input.CodArm, input.CodRua)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao gerar mapa de ocupação", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mapa)
+}
+
+// operationVisible aplica ao detalhe as regras da listagem do histórico: sem HISTGER só as
+// próprias operações, e todos os armazéns (origem e destino) precisam estar liberados
+func operationVisible(perms *sankhya.UserPermissions, detail *sankhya.OperationDetail) bool {
+	if perms.HistoryUserFilter(detail.CodUsu) != detail.CodUsu {
+		return false
+	}
+	for _, it := range detail.Itens {
+		if !perms.CanAccessWarehouse(it.CodArm) {
+			return false
+		}
+		if it.ArmDes > 0 && !perms.CanAccessWarehouse(it.ArmDes) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"zenith-go/internal/report"
+	"zenith-go/internal/sankhya"
+)
+
+type conferenceReportInput struct {
+	NumeroFechamento int    `json:"numero_fechamento"`
+	Format           string `json:"format"` // pdf (padrão) ou html
+}
+
+// HandleConferenceReport gera o relatório de conferência de um fechamento em PDF (download)
+// ou HTML (exibido no navegador, pronto para impressão)
+func (h *ExportHandler) HandleConferenceReport(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 40*time.Second)
+	defer cancel()
+
+	codUsu, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var input conferenceReportInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+	if input.NumeroFechamento == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "Número do fechamento é obrigatório", nil)
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(input.Format))
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "pdf" && format != "html" {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, fmt.Sprintf("Formato inválido: '%s' (use pdf ou html)", input.Format), nil)
+		return
+	}
+
+	data, err := h.Client.GetConferenceReport(ctx, input.NumeroFechamento)
+	if err != nil {
+		if errors.Is(err, sankhya.ErrConfNaoEncontrada) {
+			RespondError(w, r, h.Notifier, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar dados da conferência", err)
+		return
+	}
+
+	// Renderiza em memória para poder responder erro antes de enviar os headers do arquivo
+	view := conferenceReportView(data)
+	var buf bytes.Buffer
+	if format == "pdf" {
+		err = report.RenderConferencePDF(&buf, view)
+	} else {
+		err = report.RenderConferenceHTML(&buf, view)
+	}
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao gerar relatório de conferência", err)
+		return
+	}
+
+	slog.Info("Relatório de conferência", "user", codUsu, "fechamento", input.NumeroFechamento, "format", format, "itens", len(view.Itens), "finalizada", view.Finalizada)
+
+	filename := fmt.Sprintf("conferencia_%d_%s.%s", input.NumeroFechamento, time.Now().Format("20060102_150405"), format)
+	if format == "pdf" {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(buf.Bytes())
+}
+
+// conferenceReportView converte os dados do ERP para o modelo do relatório
+func conferenceReportView(data *sankhya.ConferenceReport) *report.Conference {
+	rom := data.Romaneio
+	view := &report.Conference{
+		Fechamento: rom.Fechamento,
+		NuUnico:    rom.NuUnico,
+		Data:       rom.Data,
+		Motorista:  rom.Motorista,
+		Placa:      rom.Placa,
+		Veiculo:    rom.Veiculo,
+		Paletes:    rom.Paletes,
+		PesoTotal:  rom.PesoTotal,
+		Operador:   rom.NomeUsuario,
+		Inicio:     data.Inicio,
+		Fim:        data.Fim,
+		Finalizada: data.Finalizada,
+		Observacao: data.ObsFim,
+		GeradoEm:   time.Now(),
+	}
+	if rom.CodUsuario > 0 {
+		view.Operador = fmt.Sprintf("%d - %s", rom.CodUsuario, rom.NomeUsuario)
+	}
+
+	for _, it := range data.Itens {
+		view.Itens = append(view.Itens, report.ConferenceItem{
+			NumReg:      it.NumReg,
+			Produto:     strings.TrimSpace(it.CodigoProduto),
+			Descricao:   it.Descricao,
+			Unidade:     it.Unidade,
+			Esperado:    it.Quantidade,
+			Conferido:   it.QtdEmbarcada,
+			Conferida:   it.Conferido == "S",
+			ConferidoEm: it.ConferidoEm,
+			Obs:         it.Obs,
+		})
+	}
+
+	if p := data.Progresso; p != nil {
+		view.Linhas = report.Totals{Esperado: p.Linhas.Esperado, Conferido: p.Linhas.Conferido}
+		view.Quantidade = report.Totals{Esperado: p.Quantidade.Esperado, Conferido: p.Quantidade.Conferido}
+		view.PesoBruto = report.Totals{Esperado: p.PesoBruto.Esperado, Conferido: p.PesoBruto.Conferido}
+		view.Percentual = p.Percentual
+		for _, d := range p.Divergencias {
+			view.Divergencias = append(view.Divergencias, report.Divergence{
+				Tipo:      d.Tipo,
+				NumReg:    d.NumReg,
+				Produto:   strconv.Itoa(d.CodProd),
+				Descricao: d.Descricao,
+				Unidade:   d.Unidade,
+				Esperado:  d.Quantidade,
+				Conferido: d.QtdEmbarcada,
+				Diferenca: d.Diferenca,
+			})
+		}
+	}
+	return view
+}
@@ -3,10 +3,13 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"time"
 	"strings"
 	"zenith-go/internal/auth"
+	"zenith-go/internal/conference"
 	"zenith-go/internal/config"
 	"zenith-go/internal/notification"
 	"zenith-go/internal/sankhya"
@@ -17,6 +20,9 @@ type RomaneioHandler struct {
 	Config   *config.Config
 	Session  *auth.SessionManager
 	Notifier *notification.EmailService
+	Locks    *conference.Locks
+	Events   *conference.Bus
+	Streams  context.Context // Cancelado no desligamento do servidor para encerrar os streams SSE
 }
 
 func (h *RomaneioHandler) HandleGetRomaneios(w http.ResponseWriter, r *http.Request) {
@@ -69,6 +75,113 @@ func (h *RomaneioHandler) HandleGetRomaneios(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(data)
 }
 
+// HandleSearchRomaneios busca romaneios por período, situação da conferência, motorista,
+// placa, veículo e conferente, com paginação (inclui os já conferidos)
+func (h *RomaneioHandler) HandleSearchRomaneios(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeader(r)
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return
+	}
+
+	codUsu, _, err := auth.ValidateToken(token, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.RomaneioSearchQuery
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	if input.DtIni == "" || input.DtFim == "" {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "dtIni e dtFim são obrigatórios", nil)
+		return
+	}
+
+	slog.Info("Busca de romaneios", "user", codUsu, "dtIni", input.DtIni, "dtFim", input.DtFim, "status", input.Status, "limit", input.Limit, "offset", input.Offset)
+
+	page, err := h.Client.SearchRomaneios(ctx, input)
+	if err != nil {
+		if errors.Is(err, sankhya.ErrRomaneioFiltro) {
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao buscar romaneios", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// HandleGetConferenceProgress mostra linhas/quantidades/peso conferidos e as divergências,
+// para o operador e o supervisor verem o que falta antes de finalizar
+func (h *RomaneioHandler) HandleGetConferenceProgress(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := getTokenFromHeader(r)
+	if token == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token ausente", nil)
+		return
+	}
+
+	if _, _, err := auth.ValidateToken(token, h.Config.JwtSecret); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+
+	if err := h.Session.ValidateAndUpdate(token); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	var input sankhya.ConferenceProgressInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	if input.NuUnico == 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "O campo 'nu_unico' é obrigatório", nil)
+		return
+	}
+
+	progress, err := h.Client.GetConferenceProgress(ctx, input.NuUnico)
+	if err != nil {
+		if errors.Is(err, sankhya.ErrConfNaoEncontrada) {
+			RespondError(w, r, h.Notifier, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao calcular andamento da conferência", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
 func getTokenFromHeaderRomaneio(r *http.Request) string {
 	authHeader := r.Header.Get("Authorization")
 	if strings.HasPrefix(authHeader, "Bearer ") {
@@ -97,7 +210,8 @@ func (h *RomaneioHandler) HandleIniciarConferencia(w http.ResponseWriter, r *htt
 	}
 
 	// 2. Validação do JWT
-	if _, _, err := auth.ValidateToken(bearerToken, h.Config.JwtSecret); err != nil {
+	codUsu, username, err := auth.ValidateToken(bearerToken, h.Config.JwtSecret)
+	if err != nil {
 		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
 		return
 	}
@@ -120,13 +234,23 @@ func (h *RomaneioHandler) HandleIniciarConferencia(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// 5. Execução (Passando o snkSessionId)
+	// 5. Posse exclusiva do romaneio (usuário + coletor)
 	ctx := r.Context()
+	created, ok := h.claimConference(ctx, w, r, input.NuUnico, conferenceHolder(r, bearerToken, codUsu, username))
+	if !ok {
+		return
+	}
+
+	// 6. Execução (Passando o snkSessionId)
 	resp, err := h.Client.IniciarConferencia(ctx, input.NuUnico, snkSessionId)
 	if err != nil {
+		if created {
+			h.Locks.Release(ctx, input.NuUnico)
+		}
 		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao iniciar conferência", err)
 		return
 	}
+	h.publishConference(conference.EventoConferenciaIniciada, input.NuUnico, codUsu, username, nil)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
@@ -147,7 +271,8 @@ func (h *RomaneioHandler) HandleConferirItem(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if _, _, err := auth.ValidateToken(bearerToken, h.Config.JwtSecret); err != nil {
+	codUsu, username, err := auth.ValidateToken(bearerToken, h.Config.JwtSecret)
+	if err != nil {
 		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
 		return
 	}
@@ -176,18 +301,138 @@ func (h *RomaneioHandler) HandleConferirItem(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// 4. Execução (Passando o input completo)
+	// 4. Posse da conferência (renova a cada leitura)
 	ctx := r.Context()
+	if _, ok := h.claimConference(ctx, w, r, input.NuUnico, conferenceHolder(r, bearerToken, codUsu, username)); !ok {
+		return
+	}
+
+	// 5. Estado da linha antes da STP, para o histórico registrar a quantidade anterior
+	lines, err := h.Client.GetConferenceLines(ctx, input.NuUnico)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao carregar itens da conferência", err)
+		return
+	}
+	var qtdAnt *float64
+	found := false
+	for _, l := range lines {
+		if l.NumReg == input.NumReg {
+			found = true
+			if l.Conferido == "S" {
+				qtdAnt = &l.QtdEmbarcada
+			}
+			break
+		}
+	}
+	if !found {
+		RespondError(w, r, h.Notifier, http.StatusNotFound, sankhya.ErrConfItemNaoEncontrado.Error(), nil)
+		return
+	}
+
+	// 6. Execução (Passando o input completo)
 	resp, err := h.Client.ConferirItem(ctx, input, snkSessionId)
 	if err != nil {
 		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao conferir item", err)
 		return
 	}
+	h.logConferenceItem(ctx, input.NuUnico, input.NumReg, codUsu, qtdAnt, input.QtdEmbarcada, input.Obs)
+	h.publishConference(conference.EventoItemConferido, input.NuUnico, codUsu, username, conference.ItemConferido{
+		NumReg:       input.NumReg,
+		QtdEmbarcada: input.QtdEmbarcada,
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
+// HandleConferirPorCodigo confere a partir do código lido: o servidor resolve a linha
+// (num_reg) do romaneio e responde 409 com as candidatas quando a leitura é ambígua
+func (h *RomaneioHandler) HandleConferirPorCodigo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 1. Extração e Validação dos Tokens
+	bearerToken := getTokenFromHeaderRomaneio(r)
+	snkSessionId := getHeaderRomaneio(r, "Snkjsessionid")
+
+	if bearerToken == "" || snkSessionId == "" {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Tokens ausentes (Authorization ou Snkjsessionid)", nil)
+		return
+	}
+
+	codUsu, username, err := auth.ValidateToken(bearerToken, h.Config.JwtSecret)
+	if err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
+		return
+	}
+
+	if err := h.Session.ValidateAndUpdate(bearerToken); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Sessão expirada", err)
+		return
+	}
+
+	// 2. Parsing do Body
+	var input sankhya.ConferirPorCodigoInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "JSON inválido", err)
+		return
+	}
+
+	if input.NuUnico == 0 || strings.TrimSpace(input.Codigo) == "" {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "Os campos 'nu_unico' e 'codigo' são obrigatórios", nil)
+		return
+	}
+	if input.QtdEmbarcada < 0 {
+		RespondError(w, r, h.Notifier, http.StatusBadRequest, "O campo 'qtd_embarcada' não pode ser negativo", nil)
+		return
+	}
+
+	// 3. Posse da conferência (renova a cada leitura)
+	ctx := r.Context()
+	if _, ok := h.claimConference(ctx, w, r, input.NuUnico, conferenceHolder(r, bearerToken, codUsu, username)); !ok {
+		return
+	}
+
+	// 4. Resolução da leitura + STP
+	result, err := h.Client.ConferirPorCodigo(ctx, input, snkSessionId)
+	if err != nil {
+		var matchErr *sankhya.ConferenceMatchError
+		switch {
+		case errors.As(err, &matchErr) && errors.Is(err, sankhya.ErrConfCodigoAmbiguo):
+			RespondConferenceConflict(w, r, ErrCodeConfCodigoAmbiguo, matchErr)
+		case errors.As(err, &matchErr) && errors.Is(err, sankhya.ErrConfItemJaConferido):
+			RespondConferenceConflict(w, r, ErrCodeConfJaConferido, matchErr)
+		case errors.As(err, &matchErr):
+			RespondConferenceConflict(w, r, ErrCodeConfNumRegInvalido, matchErr)
+		case errors.Is(err, sankhya.ErrConfCodigoNaoEncontrado), errors.Is(err, sankhya.ErrConfNaoEncontrada):
+			RespondError(w, r, h.Notifier, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, sankhya.ErrConfQuantidadeInvalida):
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), nil)
+		default:
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao conferir item", err)
+		}
+		return
+	}
+	// A linha resolvida é o estado antes da leitura: se já estava conferida, é uma reconferência
+	var qtdAnt *float64
+	if result.Linha.Conferido == "S" {
+		qtdAnt = &result.Linha.QtdEmbarcada
+	}
+	h.logConferenceItem(ctx, input.NuUnico, result.Linha.NumReg, codUsu, qtdAnt, result.QtdEmbarcada, input.Obs)
+	h.publishConference(conference.EventoItemConferido, input.NuUnico, codUsu, username, conference.ItemConferido{
+		NumReg:       result.Linha.NumReg,
+		CodProd:      result.Linha.CodProd,
+		Descricao:    result.Linha.Descricao,
+		Unidade:      result.Linha.Unidade,
+		QtdEmbarcada: result.QtdEmbarcada,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 func (h *RomaneioHandler) HandleFinalizarConferencia(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
@@ -203,7 +448,8 @@ func (h *RomaneioHandler) HandleFinalizarConferencia(w http.ResponseWriter, r *h
 		return
 	}
 
-	if _, _, err := auth.ValidateToken(bearerToken, h.Config.JwtSecret); err != nil {
+	codUsu, username, err := auth.ValidateToken(bearerToken, h.Config.JwtSecret)
+	if err != nil {
 		RespondError(w, r, h.Notifier, http.StatusUnauthorized, "Token inválido", err)
 		return
 	}
@@ -226,14 +472,42 @@ func (h *RomaneioHandler) HandleFinalizarConferencia(w http.ResponseWriter, r *h
 		return
 	}
 
-	// 4. Execução
+	// 4. Só quem está com a conferência finaliza (supervisor transfere antes, se preciso)
 	ctx := r.Context()
-	resp, err := h.Client.FinalizarConferencia(ctx, input, snkSessionId)
+	created, ok := h.claimConference(ctx, w, r, input.NuUnico, conferenceHolder(r, bearerToken, codUsu, username))
+	if !ok {
+		return
+	}
+
+	// 5. Verificação das divergências + Execução
+	resp, err := h.Client.FinalizarConferenciaVerificada(ctx, input, codUsu, snkSessionId)
 	if err != nil {
-		RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao finalizar conferência", err)
+		// Posse criada só por esta tentativa (ex.: bloqueada por divergências) não pode prender o romaneio
+		if created {
+			h.Locks.Release(ctx, input.NuUnico)
+		}
+		var blocked *sankhya.ConferenceBlockedError
+		switch {
+		case errors.As(err, &blocked):
+			RespondConferenceBlocked(w, r, blocked)
+		case errors.Is(err, sankhya.ErrConfSupervisor):
+			RespondError(w, r, h.Notifier, http.StatusForbidden, err.Error(), nil)
+		case errors.Is(err, sankhya.ErrConfJustificativa):
+			RespondError(w, r, h.Notifier, http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, sankhya.ErrConfNaoEncontrada):
+			RespondError(w, r, h.Notifier, http.StatusNotFound, err.Error(), nil)
+		default:
+			RespondError(w, r, h.Notifier, http.StatusInternalServerError, "Erro ao finalizar conferência", err)
+		}
 		return
 	}
 
+	// Conferência encerrada: libera o romaneio (se falhar, a posse expira sozinha)
+	if err := h.Locks.Release(ctx, input.NuUnico); err != nil {
+		slog.Warn("Falha ao liberar posse da conferência", "nuUnico", input.NuUnico, "error", err)
+	}
+	h.publishConference(conference.EventoConferenciaFinalizada, input.NuUnico, codUsu, username, nil)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
\ No newline at end of file
@@ -89,6 +89,21 @@ func (h *TransactionHandler) HandleExecuteTransaction(w http.ResponseWriter, r *
 			return
 		}
 
+		if errors.Is(err, sankhya.ErrWarehouseNotAllowed) {
+			RespondWarehouseForbidden(w, r, err)
+			return
+		}
+
+		if errors.Is(err, sankhya.ErrCorrecaoJaPendente) {
+			RespondError(w, r, h.Notifier, http.StatusConflict, err.Error(), nil)
+			return
+		}
+
+		if errors.Is(err, sankhya.ErrGS1Invalido) || errors.Is(err, sankhya.ErrGS1ProdutoDivergente) || errors.Is(err, sankhya.ErrGS1ValidadeDivergente) {
+			RespondError(w, r, h.Notifier, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		}
+
 		status := http.StatusInternalServerError
 		if strings.Contains(err.Error(), "permissão") || strings.Contains(err.Error(), "negada") {
 			status = http.StatusForbidden
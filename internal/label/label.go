@@ -0,0 +1,180 @@
+// Package label gera etiquetas ZPL (Zebra) a partir de templates e renderiza uma prévia em PNG.
+//
+// Os templates são text/template. Para cada tipo a busca é, em ordem:
+// <dir>/<codArm>/<tipo>.zpl, <dir>/<tipo>.zpl e o template embutido no código.
+package label
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	ErrTipoInvalido     = errors.New("tipo de etiqueta inválido")
+	ErrTemplateInvalido = errors.New("template de etiqueta inválido")
+)
+
+// Kind é o tipo de etiqueta
+type Kind string
+
+const (
+	KindEndereco Kind = "endereco"
+	KindPalete   Kind = "palete"
+)
+
+func ParseKind(s string) (Kind, error) {
+	switch Kind(strings.ToLower(strings.TrimSpace(s))) {
+	case KindEndereco:
+		return KindEndereco, nil
+	case KindPalete:
+		return KindPalete, nil
+	}
+	return "", fmt.Errorf("%w: '%s'", ErrTipoInvalido, s)
+}
+
+// AddressLabel são os dados da etiqueta de endereço (identificação do rack)
+type AddressLabel struct {
+	CodArm    int
+	DesArm    string
+	SeqEnd    int
+	CodRua    string
+	CodPrd    int
+	CodApt    string
+	Picking   bool
+	CodProd   int
+	DescrProd string
+	Copias    int
+}
+
+// PalletLabel são os dados da etiqueta de palete (produto armazenado no endereço)
+type PalletLabel struct {
+	CodArm     int
+	DesArm     string
+	SeqEnd     int
+	CodRua     string
+	CodPrd     int
+	CodApt     string
+	CodProd    int
+	DescrProd  string
+	Marca      string
+	Derivacao  string
+	DatVal     string // DD/MM/YYYY
+	Quantidade float64
+	CodVol     string
+	Impressao  string // DD/MM/YYYY HH:MM
+	Copias     int
+}
+
+// Templates carrega os templates do diretório configurado (lidos a cada uso, então
+// editar um arquivo vale para a próxima etiqueta sem reiniciar a API)
+type Templates struct {
+	dir string
+}
+
+func NewTemplates(dir string) *Templates {
+	return &Templates{dir: dir}
+}
+
+// Render executa o template do tipo para o armazém. data deve ser AddressLabel ou PalletLabel.
+func (t *Templates) Render(kind Kind, codArm int, data any) (string, error) {
+	switch d := data.(type) {
+	case AddressLabel:
+		if d.Copias <= 0 {
+			d.Copias = 1
+		}
+		data = d
+	case PalletLabel:
+		if d.Copias <= 0 {
+			d.Copias = 1
+		}
+		if d.Impressao == "" {
+			d.Impressao = time.Now().Format("02/01/2006 15:04")
+		}
+		data = d
+	}
+
+	src, name, err := t.source(kind, codArm)
+	if err != nil {
+		return "", err
+	}
+
+	tpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("%w (%s): %v", ErrTemplateInvalido, name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w (%s): %v", ErrTemplateInvalido, name, err)
+	}
+	return buf.String(), nil
+}
+
+// source resolve o template: específico do armazém, padrão do diretório ou embutido
+func (t *Templates) source(kind Kind, codArm int) (string, string, error) {
+	if t.dir != "" {
+		candidates := []string{
+			filepath.Join(t.dir, strconv.Itoa(codArm), string(kind)+".zpl"),
+			filepath.Join(t.dir, string(kind)+".zpl"),
+		}
+		for _, path := range candidates {
+			content, err := os.ReadFile(path)
+			if err == nil {
+				return string(content), path, nil
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				return "", "", fmt.Errorf("falha ao ler template %s: %w", path, err)
+			}
+		}
+	}
+
+	switch kind {
+	case KindEndereco:
+		return defaultAddressTemplate, "embutido:endereco", nil
+	case KindPalete:
+		return defaultPalletTemplate, "embutido:palete", nil
+	}
+	return "", "", fmt.Errorf("%w: '%s'", ErrTipoInvalido, kind)
+}
+
+var templateFuncs = template.FuncMap{
+	"zpl":     Escape,
+	"decimal": formatDecimal,
+	"upper":   strings.ToUpper,
+	"trunc":   truncate,
+}
+
+// Escape prepara texto para um campo com ^FH: _, ^ e ~ viram escapes hexadecimais
+func Escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '_', '^', '~':
+			fmt.Fprintf(&b, "_%02X", r)
+		case '\r', '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// formatDecimal usa vírgula decimal, sem casas quando o valor é inteiro
+func formatDecimal(f float64) string {
+	return strings.Replace(strconv.FormatFloat(f, 'f', -1, 64), ".", ",", 1)
+}
+
+func truncate(max int, s string) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
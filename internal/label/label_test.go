@@ -0,0 +1,183 @@
+package label
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// modulesOf monta as larguras esperadas a partir dos valores Code 128 (start ... checksum)
+func modulesOf(codes ...int) []int {
+	var widths []int
+	for _, c := range append(codes, code128Stop) {
+		for _, w := range code128Patterns[c] {
+			widths = append(widths, int(w-'0'))
+		}
+	}
+	return widths
+}
+
+func TestCode128Patterns(t *testing.T) {
+	for i, p := range code128Patterns {
+		want := 11
+		if i == code128Stop {
+			want = 13
+		}
+		sum := 0
+		for _, w := range p {
+			sum += int(w - '0')
+		}
+		if sum != want {
+			t.Errorf("padrão %d (%s) soma %d módulos, quer %d", i, p, sum, want)
+		}
+	}
+
+	known := map[int]string{code128StartB: "211214", code128StartC: "211232", code128Stop: "2331112", code128CodeB: "114131", code128CodeC: "113141"}
+	for code, want := range known {
+		if code128Patterns[code] != want {
+			t.Errorf("padrão %d = %s, quer %s", code, code128Patterns[code], want)
+		}
+	}
+}
+
+func TestCode128Modules(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		codes []int // start, dados e checksum
+	}{
+		{"dígitos pares em C", "12345678", []int{code128StartC, 12, 34, 56, 78, 47}},
+		{"dois dígitos em C", "12", []int{code128StartC, 12, 14}},
+		{"dígitos ímpares terminam em B", "12345", []int{code128StartC, 12, 34, code128CodeB, 21, 54}},
+		{"poucos dígitos ficam em B", "123", []int{code128StartB, 17, 18, 19, 8}},
+		{"texto e sequência par no fim", "ABC-123456", []int{code128StartB, 33, 34, 35, 13, code128CodeC, 12, 34, 56, 70}},
+		{"sequência ímpar no fim: primeiro dígito em B", "A12345", []int{code128StartB, 33, 17, code128CodeC, 23, 45, 64}},
+		{"sequência curta no meio não troca", "AB1234CD", []int{code128StartB, 33, 34, 17, 18, 19, 20, 35, 36, 46}},
+		{"alfanumérico", "PJJ123C", []int{code128StartB, 48, 42, 42, 17, 18, 19, 35, 55}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := code128Modules(tt.data)
+			if err != nil {
+				t.Fatalf("code128Modules(%q): %v", tt.data, err)
+			}
+			if want := modulesOf(tt.codes...); !reflect.DeepEqual(got, want) {
+				t.Errorf("code128Modules(%q)\n got %v\nwant %v", tt.data, got, want)
+			}
+		})
+	}
+}
+
+func TestCode128ModulesErrors(t *testing.T) {
+	for _, data := range []string{"", "AÇÃO", "A\tB"} {
+		if _, err := code128Modules(data); err == nil {
+			t.Errorf("code128Modules(%q) deveria falhar", data)
+		}
+	}
+}
+
+func TestEscapeDecodeHex(t *testing.T) {
+	tests := []struct {
+		in      string
+		escaped string
+		decoded string
+	}{
+		{"RUA_01", "RUA_5F01", "RUA_01"},
+		{"A^B~C", "A_5EB_7EC", "A^B~C"},
+		{"_^~", "_5F_5E_7E", "_^~"},
+		{"SEM ESCAPE", "SEM ESCAPE", "SEM ESCAPE"},
+		{"LINHA\r\nNOVA", "LINHA  NOVA", "LINHA  NOVA"},
+	}
+	for _, tt := range tests {
+		got := Escape(tt.in)
+		if got != tt.escaped {
+			t.Errorf("Escape(%q) = %q, quer %q", tt.in, got, tt.escaped)
+		}
+		if back := decodeHex(got); back != tt.decoded {
+			t.Errorf("decodeHex(%q) = %q, quer %q", got, back, tt.decoded)
+		}
+	}
+
+	// Sublinhado sem hexadecimal válido fica como está
+	for _, s := range []string{"_", "A_", "_ZZ", "_5"} {
+		if got := decodeHex(s); got != s {
+			t.Errorf("decodeHex(%q) = %q, quer inalterado", s, got)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxChars int
+		maxLines int
+		want     []string
+	}{
+		{"PARAFUSO SEXTAVADO M6", 0, 0, []string{"PARAFUSO SEXTAVADO M6"}},
+		{"PARAFUSO SEXTAVADO M6", 10, 0, []string{"PARAFUSO", "SEXTAVADO", "M6"}},
+		{"PARAFUSO SEXTAVADO M6", 12, 1, []string{"PARAFUSO"}},
+		{"ABCDEFGHIJKL MN", 5, 0, []string{"ABCDE", "FGHIJ", "KL MN"}},
+		{"  ", 10, 0, nil},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.maxChars, tt.maxLines); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapText(%q, %d, %d) = %q, quer %q", tt.text, tt.maxChars, tt.maxLines, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPreviewDefaults(t *testing.T) {
+	tpl := NewTemplates("")
+
+	tests := []struct {
+		kind    Kind
+		data    any
+		escaped []string // trechos que precisam sair com ^FH antes do ^FD
+		width   int
+		height  int
+	}{
+		{
+			kind:    KindEndereco,
+			data:    AddressLabel{CodArm: 1, DesArm: "cd_matriz", SeqEnd: 12345, CodRua: "A_1", CodPrd: 2, CodApt: "3^B", Picking: true},
+			escaped: []string{"^FH^FDCD_5FMATRIZ^FS", "^FH^FDR A_5F1  P 2  A 3_5EB^FS"},
+			width:   800,
+			height:  400,
+		},
+		{
+			kind: KindPalete,
+			data: PalletLabel{CodArm: 1, DesArm: "CD", SeqEnd: 12345, CodRua: "A", CodPrd: 2, CodApt: "3",
+				CodProd: 5050, DescrProd: "PARAFUSO ~ SEXTAVADO M6 ZINCADO", Marca: "ACME_X", Derivacao: "CX C/ 100",
+				DatVal: "31/12/2026", Quantidade: 12.5, CodVol: "CX", Impressao: "18/10/2026 10:00", Copias: 2},
+			escaped: []string{"^FH^FDPARAFUSO _7E SEXTAVADO M6 ZINCADO^FS", "^FH^FDMARCA: ACME_5FX^FS", "^FH^FD12,5 CX^FS", "^PQ2"},
+			width:   800,
+			height:  1200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			zpl, err := tpl.Render(tt.kind, 1, tt.data)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			for _, want := range tt.escaped {
+				if !strings.Contains(zpl, want) {
+					t.Errorf("ZPL sem %q:\n%s", want, zpl)
+				}
+			}
+
+			img, err := Preview(zpl)
+			if err != nil {
+				t.Fatalf("Preview: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Errorf("prévia %dx%d, quer %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestPreviewSemEtiqueta(t *testing.T) {
+	if _, err := Preview("^FO10,10^FDX^FS"); err != ErrPreviewVazio {
+		t.Errorf("Preview sem ^XA: erro = %v, quer ErrPreviewVazio", err)
+	}
+}
@@ -0,0 +1,427 @@
+package label
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+)
+
+var ErrPreviewVazio = errors.New("ZPL sem etiqueta para pré-visualizar")
+
+// Preview interpreta o subconjunto de ZPL usado nos templates e desenha a primeira
+// etiqueta (^XA...^XZ) em escala 1:1 (1 pixel = 1 ponto da impressora).
+//
+// Suportado: ^PW ^LL ^LH ^FO ^FT ^A0/^CF ^FB ^FH ^FR ^FD ^FS ^GB ^BY ^BC.
+// Demais comandos são ignorados; rotação e fontes diferentes de 0 saem como N / fonte 0.
+func Preview(zpl string) (*image.Gray, error) {
+	start := strings.Index(zpl, "^XA")
+	if start < 0 {
+		return nil, ErrPreviewVazio
+	}
+	zpl = zpl[start+3:]
+	if end := strings.Index(zpl, "^XZ"); end >= 0 {
+		zpl = zpl[:end]
+	}
+
+	p := &previewState{fontH: 30, fontW: 30, moduleW: 2, barH: 10}
+	if err := p.run(zpl); err != nil {
+		return nil, err
+	}
+	return p.render(), nil
+}
+
+type fieldBlock struct {
+	width, lines, spacing int
+	justify               byte
+}
+
+type previewState struct {
+	width, length int
+	homeX, homeY  int
+	x, y          int
+	baseline      bool
+	fontH, fontW  int
+	moduleW, barH int
+	hexIndicator  bool
+	reverse       bool
+	block         *fieldBlock
+	barcode       []string // parâmetros do ^BC pendente
+	box           []int    // parâmetros do ^GB pendente
+	data          *string
+	ops           []func(img *image.Gray)
+	maxX, maxY    int
+}
+
+func (p *previewState) run(zpl string) error {
+	i := 0
+	for i < len(zpl) {
+		c := zpl[i]
+		if c != '^' && c != '~' {
+			i++
+			continue
+		}
+		if i+3 > len(zpl) {
+			break
+		}
+		cmd := strings.ToUpper(zpl[i+1 : i+3])
+		i += 3
+
+		// ^FD vai até o próximo ^FS (o conteúdo pode ter vírgulas e espaços)
+		if cmd == "FD" {
+			end := strings.Index(zpl[i:], "^FS")
+			if end < 0 {
+				end = len(zpl) - i
+			}
+			data := zpl[i : i+end]
+			p.data = &data
+			i += end
+			continue
+		}
+
+		end := strings.IndexAny(zpl[i:], "^~")
+		if end < 0 {
+			end = len(zpl) - i
+		}
+		args := strings.TrimSpace(strings.NewReplacer("\r", "", "\n", "").Replace(zpl[i : i+end]))
+		i += end
+
+		if err := p.command(cmd, splitArgs(args)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *previewState) command(cmd string, args []string) error {
+	switch {
+	case cmd == "PW":
+		p.width = argInt(args, 0, 0)
+	case cmd == "LL":
+		p.length = argInt(args, 0, 0)
+	case cmd == "LH":
+		p.homeX, p.homeY = argInt(args, 0, 0), argInt(args, 1, 0)
+	case cmd == "FO" || cmd == "FT":
+		p.x, p.y = p.homeX+argInt(args, 0, 0), p.homeY+argInt(args, 1, 0)
+		p.baseline = cmd == "FT"
+	case cmd == "CF":
+		p.fontH = argInt(args, 1, p.fontH)
+		p.fontW = argInt(args, 2, p.fontH)
+	case cmd[0] == 'A' && cmd != "A@":
+		// ^A0N,h,w: o segundo caractere do comando é a fonte, o primeiro argumento a orientação
+		p.fontH = argInt(args, 1, p.fontH)
+		p.fontW = argInt(args, 2, p.fontH)
+	case cmd == "FB":
+		fb := &fieldBlock{width: argInt(args, 0, 0), lines: argInt(args, 1, 1), spacing: argInt(args, 2, 0), justify: 'L'}
+		if j := argString(args, 3); j != "" {
+			fb.justify = strings.ToUpper(j)[0]
+		}
+		p.block = fb
+	case cmd == "FH":
+		p.hexIndicator = true
+	case cmd == "FR":
+		p.reverse = true
+	case cmd == "BY":
+		p.moduleW = argInt(args, 0, p.moduleW)
+		p.barH = argInt(args, 2, p.barH)
+	case cmd == "BC":
+		p.barcode = append([]string{}, args...)
+		if len(p.barcode) == 0 {
+			p.barcode = []string{"N"}
+		}
+	case cmd == "GB":
+		p.box = []int{argInt(args, 0, 1), argInt(args, 1, 1), argInt(args, 2, 1)}
+		if strings.EqualFold(argString(args, 3), "W") {
+			p.box = append(p.box, 0)
+		}
+	case cmd == "FS":
+		return p.flushField()
+	}
+	return nil
+}
+
+// flushField desenha o campo acumulado desde o ^FO e limpa o estado do campo
+func (p *previewState) flushField() error {
+	defer func() {
+		p.data, p.barcode, p.box, p.block = nil, nil, nil, nil
+		p.hexIndicator, p.reverse, p.baseline = false, false, false
+	}()
+
+	text := ""
+	if p.data != nil {
+		text = *p.data
+		if p.hexIndicator {
+			text = decodeHex(text)
+		}
+		text = accentFold.Replace(text)
+	}
+
+	switch {
+	case p.box != nil:
+		p.addBox(p.x, p.y, p.box)
+	case p.barcode != nil:
+		return p.addBarcode(text)
+	case p.data != nil:
+		p.addText(text)
+	}
+	return nil
+}
+
+func (p *previewState) addBox(x, y int, box []int) {
+	w, h, t := box[0], box[1], box[2]
+	if w < t {
+		w = t
+	}
+	if h < t {
+		h = t
+	}
+	white := len(box) > 3
+	reverse := p.reverse
+	p.extend(x+w, y+h)
+	p.ops = append(p.ops, func(img *image.Gray) {
+		rects := []image.Rectangle{
+			image.Rect(x, y, x+w, y+t), image.Rect(x, y+h-t, x+w, y+h),
+			image.Rect(x, y, x+t, y+h), image.Rect(x+w-t, y, x+w, y+h),
+		}
+		for _, r := range rects {
+			switch {
+			case reverse:
+				invert(img, r)
+			case white:
+				fill(img, r, color.Gray{Y: 255})
+			default:
+				fill(img, r, color.Gray{Y: 0})
+			}
+		}
+	})
+}
+
+func (p *previewState) addBarcode(data string) error {
+	widths, err := code128Modules(data)
+	if err != nil {
+		return err
+	}
+
+	h := argInt(p.barcode, 1, p.barH)
+	showText := !strings.EqualFold(argString(p.barcode, 2), "N")
+	above := strings.EqualFold(argString(p.barcode, 3), "Y")
+
+	x, y, m := p.x, p.y, p.moduleW
+	if p.baseline {
+		y -= h
+	}
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	p.extend(x+total*m, y+h)
+
+	p.ops = append(p.ops, func(img *image.Gray) {
+		cx := x
+		for idx, w := range widths {
+			if idx%2 == 0 {
+				fill(img, image.Rect(cx, y, cx+w*m, y+h), color.Gray{Y: 0})
+			}
+			cx += w * m
+		}
+	})
+
+	if showText {
+		// Linha de interpretação centralizada, na altura aproximada da fonte padrão do ^BC
+		th := 9 * m
+		if th < 18 {
+			th = 18
+		}
+		tw := th * 5 / 8
+		tx := x + (total*m-len(data)*tw)/2
+		ty := y + h + m
+		if above {
+			ty = y - th - m
+		}
+		p.addLine(data, tx, ty, th, tw, false)
+	}
+	return nil
+}
+
+func (p *previewState) addText(text string) {
+	h, w := p.fontH, p.fontW
+	if w <= 0 {
+		w = h
+	}
+	// Fonte 0 é proporcional; na prévia cada caractere ocupa ~60% da largura pedida
+	cw := w * 6 / 10
+	if cw < 1 {
+		cw = 1
+	}
+	x, y := p.x, p.y
+	if p.baseline {
+		y -= h
+	}
+
+	if p.block == nil {
+		p.addLine(text, x, y, h, cw, p.reverse)
+		return
+	}
+
+	fb := p.block
+	maxChars := len(text)
+	if fb.width > 0 {
+		maxChars = fb.width / cw
+	}
+	for n, line := range wrapText(text, maxChars, fb.lines) {
+		lx := x
+		switch fb.justify {
+		case 'C':
+			lx = x + (fb.width-len(line)*cw)/2
+		case 'R':
+			lx = x + fb.width - len(line)*cw
+		}
+		p.addLine(line, lx, y+n*(h+fb.spacing), h, cw, p.reverse)
+	}
+}
+
+// addLine desenha uma linha na fonte bitmap escalada para altura h e largura de célula cw
+func (p *previewState) addLine(text string, x, y, h, cw int, reverse bool) {
+	runes := []rune(text)
+	p.extend(x+len(runes)*cw, y+h)
+	p.ops = append(p.ops, func(img *image.Gray) {
+		sx := float64(cw) / 6
+		sy := float64(h) / 8
+		for n, r := range runes {
+			g := glyph(r)
+			ox := float64(x + n*cw)
+			for col := 0; col < 5; col++ {
+				for row := 0; row < 8; row++ {
+					if g[col]&(1<<row) == 0 {
+						continue
+					}
+					rect := image.Rect(
+						int(ox+float64(col)*sx), y+int(float64(row)*sy),
+						int(ox+float64(col+1)*sx+0.999), y+int(float64(row+1)*sy+0.999),
+					)
+					if reverse {
+						invert(img, rect)
+					} else {
+						fill(img, rect, color.Gray{Y: 0})
+					}
+				}
+			}
+		}
+	})
+}
+
+func (p *previewState) extend(x, y int) {
+	if x > p.maxX {
+		p.maxX = x
+	}
+	if y > p.maxY {
+		p.maxY = y
+	}
+}
+
+// render cria a imagem com ^PW/^LL (ou a área ocupada) e aplica os campos em ordem
+func (p *previewState) render() *image.Gray {
+	w, h := p.width, p.length
+	if w <= 0 {
+		w = p.maxX + 10
+	}
+	if h <= 0 {
+		h = p.maxY + 10
+	}
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 255}), image.Point{}, draw.Src)
+	for _, op := range p.ops {
+		op(img)
+	}
+	return img
+}
+
+func fill(img *image.Gray, r image.Rectangle, c color.Gray) {
+	draw.Draw(img, r.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func invert(img *image.Gray, r image.Rectangle) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255 - img.GrayAt(x, y).Y})
+		}
+	}
+}
+
+func wrapText(text string, maxChars, maxLines int) []string {
+	if maxChars <= 0 {
+		return []string{text}
+	}
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		for len(word) > maxChars {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			lines = append(lines, word[:maxChars])
+			word = word[maxChars:]
+		}
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) <= maxChars:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	return lines
+}
+
+// decodeHex resolve os escapes _XX habilitados por ^FH
+func decodeHex(s string) string {
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] == '_' && i+2 < len(s) {
+			if b, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				out = append(out, byte(b))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, s[i])
+	}
+	return string(out)
+}
+
+func splitArgs(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func argString(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+func argInt(args []string, i, def int) int {
+	if v, err := strconv.Atoi(argString(args, i)); err == nil {
+		return v
+	}
+	return def
+}
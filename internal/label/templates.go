@@ -0,0 +1,46 @@
+package label
+
+// Templates embutidos para 203 dpi. Endereço: 100x50 mm; palete: 100x150 mm.
+// Campos de texto usam ^FH para que zpl possa escapar _, ^ e ~.
+
+const defaultAddressTemplate = `^XA
+^CI28
+^PW800
+^LL400
+^LH0,0
+^FO20,20^GB760,360,3^FS
+^FO40,40^A0N,30,30^FH^FD{{zpl (upper .DesArm)}}^FS
+^FO40,100^A0N,80,70^FH^FDR {{zpl .CodRua}}  P {{.CodPrd}}  A {{zpl .CodApt}}^FS
+{{- if .Picking}}
+^FO620,40^GB140,50,50^FS
+^FO640,50^A0N,36,36^FR^FDPICK^FS
+{{- end}}
+^FO40,200^BY3,3,120^BCN,120,Y,N,N,A^FD{{.SeqEnd}}^FS
+^PQ{{.Copias}}
+^XZ
+`
+
+const defaultPalletTemplate = `^XA
+^CI28
+^PW800
+^LL1200
+^LH0,0
+^FO20,20^GB760,1160,3^FS
+^FO40,40^A0N,30,30^FH^FD{{zpl (upper .DesArm)}} - END {{.SeqEnd}} ({{zpl .CodRua}}-{{.CodPrd}}-{{zpl .CodApt}})^FS
+^FO40,100^A0N,60,60^FDPRODUTO {{.CodProd}}^FS
+^FO40,180^FB720,3,10,L^A0N,50,45^FH^FD{{zpl (trunc 90 .DescrProd)}}^FS
+^FO40,360^A0N,36,36^FH^FDMARCA: {{zpl .Marca}}^FS
+{{- if .Derivacao}}
+^FO40,410^A0N,36,36^FH^FD{{zpl .Derivacao}}^FS
+{{- end}}
+^FO20,470^GB760,3,3^FS
+^FO40,500^A0N,36,36^FDVALIDADE^FS
+^FO40,545^A0N,90,80^FD{{if .DatVal}}{{.DatVal}}{{else}}--/--/----{{end}}^FS
+^FO40,680^A0N,36,36^FDQUANTIDADE^FS
+^FO40,725^A0N,90,80^FH^FD{{decimal .Quantidade}} {{zpl .CodVol}}^FS
+^FO20,850^GB760,3,3^FS
+^FO80,880^BY3,3,180^BCN,180,Y,N,N,A^FD{{.CodProd}}^FS
+^FO40,1130^A0N,24,24^FDIMPRESSO EM {{.Impressao}}^FS
+^PQ{{.Copias}}
+^XZ
+`
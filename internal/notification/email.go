@@ -3,6 +3,7 @@ package notification
 import (
 	"crypto/tls"
 	"fmt"
+	"html"
 	"log/slog"
 	"net/smtp"
 	"regexp"
@@ -335,6 +336,98 @@ func (s *EmailService) SendError(err error, contextInfo map[string]string) {
 	}()
 }
 
+// ExpiryDigestItem é uma linha do resumo de validade, já formatada para exibição
+type ExpiryDigestItem struct {
+	Armazem       string
+	Endereco      string
+	Produto       string
+	Marca         string
+	Validade      string
+	DiasRestantes int
+	Quantidade    string
+}
+
+// Limite de linhas no corpo do e-mail; o restante fica no relatório da API
+const expiryDigestMaxRows = 300
+
+// SendExpiryDigest envia o resumo diário de endereços vencidos ou a vencer em até `dias` dias
+func (s *EmailService) SendExpiryDigest(recipients []string, dias int, items []ExpiryDigestItem) error {
+	if !s.cfg.EmailEnabled {
+		return fmt.Errorf("envio de e-mail desabilitado no .env")
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("nenhum destinatário configurado para o resumo de validade")
+	}
+
+	vencidos := 0
+	for _, item := range items {
+		if item.DiasRestantes < 0 {
+			vencidos++
+		}
+	}
+
+	title := "Resumo de Validade"
+	subject := fmt.Sprintf("📅 [Zenith-Go] Validade: %d vencido(s), %d a vencer em %d dias", vencidos, len(items)-vencidos, dias)
+
+	rowsHtml := ""
+	for i, item := range items {
+		if i == expiryDigestMaxRows {
+			rowsHtml += fmt.Sprintf(`<tr><td colspan="7" style="padding: 8px; color: #888;">... e mais %d endereço(s). Consulte o relatório completo no Zenith.</td></tr>`, len(items)-expiryDigestMaxRows)
+			break
+		}
+
+		prazo := fmt.Sprintf("%d dia(s)", item.DiasRestantes)
+		style := ""
+		if item.DiasRestantes < 0 {
+			prazo = fmt.Sprintf("Vencido há %d dia(s)", -item.DiasRestantes)
+			style = ` style="color: #D32F2F; font-weight: bold;"`
+		} else if item.DiasRestantes == 0 {
+			prazo = "Vence hoje"
+			style = ` style="color: #D32F2F;"`
+		}
+
+		rowsHtml += fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td%s>%s</td><td style="text-align: right;">%s</td></tr>`,
+			html.EscapeString(item.Armazem), html.EscapeString(item.Endereco), html.EscapeString(item.Produto),
+			html.EscapeString(item.Marca), item.Validade, style, prazo, item.Quantidade)
+	}
+
+	bodyContent := fmt.Sprintf(`
+		<h2 style="color: #00529B; border-bottom-color: #f0f0f0;">Endereços vencidos ou a vencer em até %d dias</h2>
+		<dl class="details-grid">
+			<dt>Data:</dt>
+			<dd>%s</dd>
+			<dt>Vencidos:</dt>
+			<dd>%d</dd>
+			<dt>A vencer:</dt>
+			<dd>%d</dd>
+		</dl>
+		<div class="code-block">
+			<table style="width: 100%%; border-collapse: collapse; font-size: 13px;" cellpadding="6" border="1" bordercolor="#e0e0e0">
+				<tr style="background-color: #f4f7f6;"><th>Armazém</th><th>Endereço</th><th>Produto</th><th>Marca</th><th>Validade</th><th>Prazo</th><th>Quantidade</th></tr>
+				%s
+			</table>
+		</div>
+	`, dias, time.Now().Format("02/01/2006"), vencidos, len(items)-vencidos, rowsHtml)
+
+	fullHtml := s.getHtmlTemplate(title, bodyContent)
+
+	headers := make(map[string]string)
+	headers["From"] = s.cfg.SMTPUser
+	headers["To"] = strings.Join(recipients, ",")
+	headers["Subject"] = subject
+	headers["MIME-Version"] = "1.0"
+	headers["Content-Type"] = "text/html; charset=\"UTF-8\""
+
+	message := ""
+	for k, v := range headers {
+		message += fmt.Sprintf("%s: %s\r\n", k, v)
+	}
+	message += "\r\n" + fullHtml
+
+	slog.Info("Enviando resumo de validade...", "to", recipients, "itens", len(items))
+	return s.sendMail(recipients, []byte(message))
+}
+
 func truncate(s string, max int) string {
 	if len(s) > max {
 		return s[:max] + "..."
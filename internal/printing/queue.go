@@ -0,0 +1,346 @@
+// Package printing envia etiquetas ZPL para impressoras de rede (raw TCP, porta 9100).
+//
+// A fila fica no Redis para que os dois nós atrás do NGINX compartilhem os jobs:
+// qualquer nó pode receber o pedido, imprimir e responder a consulta de status.
+package printing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrJobNaoEncontrado = errors.New("job de impressão não encontrado")
+	ErrFilaIndisponivel = errors.New("fila de impressão indisponível")
+)
+
+// Status do job
+const (
+	StatusPendente   = "pendente"   // Na fila (ou aguardando nova tentativa)
+	StatusImprimindo = "imprimindo" // Enviando para a impressora
+	StatusConcluido  = "concluido"  // Impressora recebeu todo o ZPL
+	StatusErro       = "erro"       // Esgotou as tentativas
+)
+
+const (
+	queueKey      = "print:queue"       // Lista de IDs prontos para envio
+	retryKey      = "print:retry"       // Sorted set: ID -> horário da próxima tentativa
+	processingKey = "print:processing:" // Lista por nó: IDs retirados da fila e ainda em envio
+	nodesKey      = "print:nodes"       // Set dos nós que já consumiram a fila
+	nodeKey       = "print:node:"       // Heartbeat do nó; expirado = nó caiu
+	jobKey        = "print:job:"
+	zplKey        = "print:zpl:"
+	jobTTL        = 24 * time.Hour
+	baseBackoff   = 5 * time.Second
+	nodeTTL       = 30 * time.Second
+	reapInterval  = 10 * time.Second
+)
+
+// Job é o estado de uma impressão, devolvido ao coletor na consulta de status
+type Job struct {
+	ID            string `json:"id"`
+	Impressora    string `json:"impressora"`
+	Endereco      string `json:"-"`
+	CodArm        int    `json:"codArm"`
+	Tipo          string `json:"tipo"`
+	Etiquetas     int    `json:"etiquetas"`
+	CodUsu        int    `json:"codUsu"`
+	Status        string `json:"status"`
+	Tentativas    int    `json:"tentativas"`
+	MaxTentativas int    `json:"maxTentativas"`
+	Erro          string `json:"erro,omitempty"`
+	ProximaEm     string `json:"proximaTentativa,omitempty"`
+	CriadoEm      string `json:"criadoEm"`
+	AtualizadoEm  string `json:"atualizadoEm"`
+}
+
+// jobRecord é o que fica no Redis (Endereco precisa ser persistido, mas não vai para o JSON da API)
+type jobRecord struct {
+	Job
+	Endereco string `json:"endereco"`
+}
+
+type Queue struct {
+	rdb         *redis.Client
+	maxAttempts int
+	timeout     time.Duration
+	node        string // Identifica este processo; muda a cada deploy
+}
+
+func NewQueue(rdb *redis.Client, maxAttempts int, timeout time.Duration) *Queue {
+	host, _ := os.Hostname()
+	return &Queue{rdb: rdb, maxAttempts: maxAttempts, timeout: timeout, node: host + "-" + uuid.NewString()[:8]}
+}
+
+// Enqueue grava o ZPL e coloca o job na fila. Retorna o job com status pendente.
+func (q *Queue) Enqueue(ctx context.Context, job Job, zpl string) (*Job, error) {
+	now := time.Now().Format(time.RFC3339)
+	job.ID = uuid.NewString()
+	job.Status = StatusPendente
+	job.MaxTentativas = q.maxAttempts
+	job.CriadoEm = now
+	job.AtualizadoEm = now
+
+	data, err := json.Marshal(jobRecord{Job: job, Endereco: job.Endereco})
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := q.rdb.TxPipeline()
+	pipe.Set(ctx, zplKey+job.ID, zpl, jobTTL)
+	pipe.Set(ctx, jobKey+job.ID, data, jobTTL)
+	pipe.RPush(ctx, queueKey, job.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFilaIndisponivel, err)
+	}
+	return &job, nil
+}
+
+// Get consulta o status de um job
+func (q *Queue) Get(ctx context.Context, id string) (*Job, error) {
+	rec, err := q.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &rec.Job, nil
+}
+
+func (q *Queue) load(ctx context.Context, id string) (*jobRecord, error) {
+	data, err := q.rdb.Get(ctx, jobKey+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrJobNaoEncontrado
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFilaIndisponivel, err)
+	}
+
+	var rec jobRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	rec.Job.Endereco = rec.Endereco
+	return &rec, nil
+}
+
+func (q *Queue) save(ctx context.Context, rec *jobRecord) error {
+	rec.AtualizadoEm = time.Now().Format(time.RFC3339)
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return q.rdb.Set(ctx, jobKey+rec.ID, data, jobTTL).Err()
+}
+
+// Start sobe os workers de envio, o agendador de novas tentativas e o heartbeat do nó
+// (que também devolve à fila os jobs de nós que caíram). Param quando ctx é cancelado.
+func (q *Queue) Start(ctx context.Context, workers int) {
+	q.heartbeat(ctx)
+	for i := 0; i < workers; i++ {
+		go q.worker(ctx)
+	}
+	go q.retryScheduler(ctx)
+	go q.reaper(ctx)
+}
+
+// worker move o ID da fila para a lista de processamento do nó (BLMOVE), em vez de retirá-lo:
+// se o nó cair no meio do envio, o reaper de outro nó devolve o job à fila
+func (q *Queue) worker(ctx context.Context) {
+	processing := processingKey + q.node
+	for ctx.Err() == nil {
+		id, err := q.rdb.BLMove(ctx, queueKey, processing, "LEFT", "RIGHT", 5*time.Second).Result()
+		if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+			continue
+		}
+		if err != nil {
+			slog.Error("Fila de impressão: falha ao ler do Redis", "error", err)
+			time.Sleep(2 * time.Second)
+			continue
+		}
+		if !q.process(ctx, id) {
+			// Redis falhou no meio: volta para a fila em vez de perder o job
+			q.rdb.RPush(ctx, queueKey, id)
+			time.Sleep(2 * time.Second)
+		}
+		q.rdb.LRem(ctx, processing, 1, id)
+	}
+}
+
+// process envia um job e decide entre concluir, reagendar ou marcar erro.
+// Retorna false quando o Redis falhou antes do envio e o job deve voltar para a fila.
+func (q *Queue) process(ctx context.Context, id string) bool {
+	rec, err := q.load(ctx, id)
+	if errors.Is(err, ErrFilaIndisponivel) {
+		slog.Error("Fila de impressão: falha ao carregar job", "id", id, "error", err)
+		return false
+	}
+	if err != nil {
+		slog.Warn("Fila de impressão: job descartado", "id", id, "error", err)
+		return true
+	}
+	zpl, err := q.rdb.Get(ctx, zplKey+id).Result()
+	if errors.Is(err, redis.Nil) {
+		rec.Status = StatusErro
+		rec.Erro = "conteúdo ZPL do job não encontrado (expirado)"
+		rec.ProximaEm = ""
+		q.save(ctx, rec)
+		slog.Error("Fila de impressão: ZPL do job não encontrado", "id", id, "impressora", rec.Impressora)
+		return true
+	}
+	if err != nil {
+		slog.Error("Fila de impressão: falha ao carregar ZPL", "id", id, "error", err)
+		return false
+	}
+
+	rec.Status = StatusImprimindo
+	rec.Tentativas++
+	rec.ProximaEm = ""
+	q.save(ctx, rec)
+
+	sendErr := Send(ctx, rec.Endereco, zpl, q.timeout)
+	if sendErr == nil {
+		rec.Status = StatusConcluido
+		rec.Erro = ""
+		q.save(ctx, rec)
+		q.rdb.Del(ctx, zplKey+id)
+		slog.Info("Etiquetas impressas", "job", id, "impressora", rec.Impressora, "etiquetas", rec.Etiquetas, "tentativas", rec.Tentativas)
+		return true
+	}
+
+	rec.Erro = sendErr.Error()
+	if rec.Tentativas >= rec.MaxTentativas {
+		rec.Status = StatusErro
+		q.save(ctx, rec)
+		slog.Error("Impressão falhou após todas as tentativas", "job", id, "impressora", rec.Impressora, "error", sendErr)
+		return true
+	}
+
+	// Backoff exponencial: 5s, 10s, 20s...
+	next := time.Now().Add(baseBackoff << (rec.Tentativas - 1))
+	rec.Status = StatusPendente
+	rec.ProximaEm = next.Format(time.RFC3339)
+	q.save(ctx, rec)
+	q.rdb.ZAdd(ctx, retryKey, redis.Z{Score: float64(next.Unix()), Member: id})
+	slog.Warn("Impressão falhou, nova tentativa agendada", "job", id, "impressora", rec.Impressora, "tentativa", rec.Tentativas, "error", sendErr)
+	return true
+}
+
+// heartbeat registra o nó e renova a chave de vida enquanto ctx estiver ativo
+func (q *Queue) heartbeat(ctx context.Context) {
+	beat := func() {
+		if err := q.rdb.Set(ctx, nodeKey+q.node, time.Now().Format(time.RFC3339), nodeTTL).Err(); err != nil && ctx.Err() == nil {
+			slog.Warn("Fila de impressão: falha ao renovar heartbeat", "node", q.node, "error", err)
+		}
+	}
+	q.rdb.SAdd(ctx, nodesKey, q.node)
+	beat()
+
+	go func() {
+		ticker := time.NewTicker(nodeTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				beat()
+			}
+		}
+	}()
+}
+
+// reaper devolve à fila os jobs que ficaram na lista de processamento de um nó sem heartbeat
+// (derrubado ou reimplantado no meio do envio). O LMOVE é atômico, então se os dois nós
+// varrerem ao mesmo tempo cada job volta uma única vez. O job pode ser impresso de novo se
+// a impressora chegou a receber o ZPL antes da queda.
+func (q *Queue) reaper(ctx context.Context) {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			nodes, err := q.rdb.SMembers(ctx, nodesKey).Result()
+			if err != nil {
+				continue
+			}
+			for _, node := range nodes {
+				if node == q.node {
+					continue
+				}
+				alive, err := q.rdb.Exists(ctx, nodeKey+node).Result()
+				if err != nil || alive == 1 {
+					continue
+				}
+				requeued := 0
+				for {
+					id, err := q.rdb.LMove(ctx, processingKey+node, queueKey, "LEFT", "RIGHT").Result()
+					if err != nil {
+						break
+					}
+					requeued++
+					slog.Warn("Fila de impressão: job recuperado de nó inativo", "job", id, "node", node)
+				}
+				// Só esquece o nó quando a lista esvaziou (redis.Nil); em erro tenta de novo no próximo ciclo
+				if n, err := q.rdb.LLen(ctx, processingKey+node).Result(); err == nil && n == 0 {
+					q.rdb.SRem(ctx, nodesKey, node)
+				}
+				if requeued > 0 {
+					slog.Info("Fila de impressão: jobs devolvidos à fila", "node", node, "jobs", requeued)
+				}
+			}
+		}
+	}
+}
+
+// retryScheduler devolve à fila os jobs cuja próxima tentativa venceu. O ZREM garante
+// que só um dos nós reenfileira cada job.
+func (q *Queue) retryScheduler(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := strconv.FormatInt(time.Now().Unix(), 10)
+			ids, err := q.rdb.ZRangeByScore(ctx, retryKey, &redis.ZRangeBy{Min: "-inf", Max: now}).Result()
+			if err != nil {
+				continue
+			}
+			for _, id := range ids {
+				if removed, err := q.rdb.ZRem(ctx, retryKey, id).Result(); err == nil && removed == 1 {
+					q.rdb.RPush(ctx, queueKey, id)
+				}
+			}
+		}
+	}
+}
+
+// Send abre a conexão raw TCP com a impressora e escreve o ZPL inteiro
+func Send(ctx context.Context, addr string, zpl string, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("impressora %s inacessível: %w", addr, err)
+	}
+	defer conn.Close()
+
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write([]byte(zpl)); err != nil {
+		return fmt.Errorf("falha ao enviar para %s: %w", addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+// Package report gera documentos imprimíveis (PDF e HTML) sem dependências externas.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"zenith-go/internal/export"
+)
+
+// Conference é o relatório da conferência de um romaneio (fechamento de carga)
+type Conference struct {
+	Fechamento   int
+	NuUnico      int
+	Data         string
+	Motorista    string
+	Placa        string
+	Veiculo      string
+	Paletes      float64
+	PesoTotal    float64
+	Operador     string
+	Inicio       string
+	Fim          string
+	Finalizada   bool
+	Observacao   string
+	Itens        []ConferenceItem
+	Linhas       Totals
+	Quantidade   Totals
+	PesoBruto    Totals
+	Percentual   float64
+	Divergencias []Divergence
+	GeradoEm     time.Time
+}
+
+type ConferenceItem struct {
+	NumReg      int
+	Produto     string
+	Descricao   string
+	Unidade     string
+	Esperado    float64
+	Conferido   float64
+	Conferida   bool
+	ConferidoEm string
+	Obs         string
+}
+
+type Totals struct {
+	Esperado  float64
+	Conferido float64
+}
+
+type Divergence struct {
+	Tipo      string
+	NumReg    int
+	Produto   string
+	Descricao string
+	Unidade   string
+	Esperado  float64
+	Conferido float64
+	Diferenca float64
+}
+
+// Situacao descreve o estado da conferência no cabeçalho do relatório
+func (c *Conference) Situacao() string {
+	switch {
+	case c.Finalizada && len(c.Divergencias) > 0:
+		return "FINALIZADA COM DIVERGÊNCIAS"
+	case c.Finalizada:
+		return "FINALIZADA"
+	default:
+		return "EM ANDAMENTO (PARCIAL)"
+	}
+}
+
+// Observacoes junta a observação da finalização e as das linhas
+func (c *Conference) Observacoes() []string {
+	var obs []string
+	if s := strings.TrimSpace(c.Observacao); s != "" {
+		obs = append(obs, s)
+	}
+	for _, it := range c.Itens {
+		if s := strings.TrimSpace(it.Obs); s != "" {
+			obs = append(obs, fmt.Sprintf("#%d %s: %s", it.NumReg, it.Produto, s))
+		}
+	}
+	return obs
+}
+
+func decimal(f float64) string {
+	return export.FormatDecimal(f)
+}
+
+func signed(f float64) string {
+	if f > 0 {
+		return "+" + decimal(f)
+	}
+	return decimal(f)
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+// Layout do PDF (pontos)
+const (
+	marginX      = 36.0
+	marginTop    = 40.0
+	marginBottom = 50.0
+	contentWidth = pageWidth - 2*marginX
+	rowHeight    = 14.0
+	bodySize     = 8.5
+)
+
+// itemColumns: largura e alinhamento das colunas da tabela de itens
+var itemColumns = []struct {
+	title string
+	width float64
+	right bool
+}{
+	{"#", 28, true},
+	{"Produto", 52, false},
+	{"Descrição", 215, false},
+	{"Un", 28, false},
+	{"Esperado", 55, true},
+	{"Conferido", 55, true},
+	{"Dif.", 45, true},
+	{"Conf.", 45.28, false},
+}
+
+// pdfLayout acompanha a posição vertical e quebra as páginas
+type pdfLayout struct {
+	doc *pdfDoc
+	c   *Conference
+	y   float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.doc.addPage()
+	l.y = marginTop
+	l.doc.text(marginX, l.y, 9, true, fmt.Sprintf("Conferência do romaneio %d", l.c.Fechamento))
+	l.doc.textRight(pageWidth-marginX, l.y, 9, false, l.c.Situacao())
+	l.doc.line(marginX, l.y+5, pageWidth-marginX, l.y+5, 0.5)
+	l.y += 22
+}
+
+// ensure abre uma nova página se não couber h pontos
+func (l *pdfLayout) ensure(h float64) bool {
+	if l.y+h > pageHeight-marginBottom {
+		l.newPage()
+		return true
+	}
+	return false
+}
+
+func (l *pdfLayout) section(title string) {
+	l.ensure(40)
+	l.y += 6
+	l.doc.text(marginX, l.y, 11, true, title)
+	l.y += 14
+}
+
+func (l *pdfLayout) itemsHeader() {
+	l.doc.fillRect(marginX, l.y-10, contentWidth, rowHeight, 0.85)
+	x := marginX
+	for _, col := range itemColumns {
+		if col.right {
+			l.doc.textRight(x+col.width-4, l.y, bodySize, true, col.title)
+		} else {
+			l.doc.text(x+4, l.y, bodySize, true, col.title)
+		}
+		x += col.width
+	}
+	l.y += rowHeight
+}
+
+// RenderConferencePDF escreve o relatório em PDF (A4 retrato)
+func RenderConferencePDF(w io.Writer, c *Conference) error {
+	doc := newPDF(fmt.Sprintf("Conferência do romaneio %d", c.Fechamento))
+	l := &pdfLayout{doc: doc, c: c}
+
+	// Cabeçalho
+	doc.addPage()
+	l.y = marginTop + 8
+	doc.text(marginX, l.y, 16, true, "Relatório de Conferência de Carga")
+	doc.textRight(pageWidth-marginX, l.y, 12, true, fmt.Sprintf("Romaneio %d", c.Fechamento))
+	l.y += 16
+	doc.text(marginX, l.y, 10, false, c.Situacao())
+	doc.textRight(pageWidth-marginX, l.y, 9, false, fmt.Sprintf("Conferência %d", c.NuUnico))
+	l.y += 12
+
+	fields := [][2]string{
+		{"Data", orDash(c.Data)}, {"Motorista", orDash(c.Motorista)},
+		{"Placa", orDash(c.Placa)}, {"Veículo", orDash(c.Veiculo)},
+		{"Paletes", decimal(c.Paletes)}, {"Peso total (kg)", decimal(c.PesoTotal)},
+		{"Conferente", orDash(c.Operador)}, {"Progresso", fmt.Sprintf("%s%% das linhas", decimal(c.Percentual))},
+		{"Início", orDash(c.Inicio)}, {"Fim", orDash(c.Fim)},
+	}
+	boxTop := l.y
+	boxH := float64((len(fields)+1)/2)*18 + 8
+	doc.strokeRect(marginX, boxTop, contentWidth, boxH, 0.5)
+	half := contentWidth / 2
+	for i, f := range fields {
+		x := marginX + 8 + float64(i%2)*half
+		y := boxTop + 16 + float64(i/2)*18
+		doc.text(x, y, bodySize, true, f[0]+":")
+		doc.text(x+80, y, bodySize, false, fitText(f[1], half-96, bodySize, false))
+	}
+	l.y = boxTop + boxH + 14
+
+	// Itens
+	l.section("Itens")
+	l.itemsHeader()
+	for i, it := range c.Itens {
+		if l.ensure(rowHeight) {
+			l.itemsHeader()
+		}
+		if i%2 == 1 {
+			doc.fillRect(marginX, l.y-10, contentWidth, rowHeight, 0.95)
+		}
+		conf := "Não"
+		if it.Conferida {
+			conf = "Sim"
+		}
+		values := []string{
+			strconv.Itoa(it.NumReg), it.Produto, it.Descricao, it.Unidade,
+			decimal(it.Esperado), decimal(it.Conferido), signed(it.Conferido - it.Esperado), conf,
+		}
+		x := marginX
+		for n, col := range itemColumns {
+			v := fitText(values[n], col.width-8, bodySize, false)
+			if col.right {
+				doc.textRight(x+col.width-4, l.y, bodySize, false, v)
+			} else {
+				doc.text(x+4, l.y, bodySize, false, v)
+			}
+			x += col.width
+		}
+		l.y += rowHeight
+	}
+
+	// Totais
+	l.ensure(3 * rowHeight)
+	doc.line(marginX, l.y-8, pageWidth-marginX, l.y-8, 0.5)
+	l.y += 4
+	doc.text(marginX, l.y, bodySize, true, fmt.Sprintf("Linhas: %s de %s", decimal(c.Linhas.Conferido), decimal(c.Linhas.Esperado)))
+	doc.text(marginX+170, l.y, bodySize, true, fmt.Sprintf("Quantidade: %s de %s", decimal(c.Quantidade.Conferido), decimal(c.Quantidade.Esperado)))
+	doc.text(marginX+350, l.y, bodySize, true, fmt.Sprintf("Peso: %s de %s kg", decimal(c.PesoBruto.Conferido), decimal(c.PesoBruto.Esperado)))
+	l.y += rowHeight + 4
+
+	// Divergências
+	l.section("Divergências")
+	if len(c.Divergencias) == 0 {
+		doc.text(marginX, l.y, bodySize, false, "Nenhuma divergência.")
+		l.y += rowHeight
+	}
+	for _, d := range c.Divergencias {
+		l.ensure(rowHeight)
+		doc.text(marginX, l.y, bodySize, true, d.Tipo)
+		line := fmt.Sprintf("#%d %s %s - esperado %s, conferido %s (%s %s)",
+			d.NumReg, d.Produto, d.Descricao, decimal(d.Esperado), decimal(d.Conferido), signed(d.Diferenca), d.Unidade)
+		doc.text(marginX+90, l.y, bodySize, false, fitText(line, contentWidth-90, bodySize, false))
+		l.y += rowHeight
+	}
+
+	// Observações
+	if obs := c.Observacoes(); len(obs) > 0 {
+		l.section("Observações")
+		for _, o := range obs {
+			for _, line := range wrapWidth(o, contentWidth, bodySize, false) {
+				l.ensure(rowHeight)
+				doc.text(marginX, l.y, bodySize, false, line)
+				l.y += rowHeight - 2
+			}
+			l.y += 2
+		}
+	}
+
+	// Assinaturas
+	l.ensure(70)
+	l.y += 50
+	sigW := (contentWidth - 40) / 2
+	for i, label := range []string{"Conferente", "Motorista"} {
+		x := marginX + float64(i)*(sigW+40)
+		doc.line(x, l.y, x+sigW, l.y, 0.5)
+		doc.text(x, l.y+12, bodySize, false, label)
+	}
+
+	// Rodapé com numeração (total de páginas só é conhecido no fim)
+	generated := c.GeradoEm.Format("02/01/2006 15:04")
+	for i, page := range doc.pages {
+		doc.cur = page
+		doc.line(marginX, pageHeight-marginBottom+18, pageWidth-marginX, pageHeight-marginBottom+18, 0.3)
+		doc.text(marginX, pageHeight-marginBottom+30, 7.5, false, "Gerado em "+generated)
+		doc.textRight(pageWidth-marginX, pageHeight-marginBottom+30, 7.5, false, fmt.Sprintf("Página %d de %d", i+1, len(doc.pages)))
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
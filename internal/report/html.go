@@ -0,0 +1,121 @@
+package report
+
+import (
+	"html/template"
+	"io"
+)
+
+var conferenceHTML = template.Must(template.New("conferencia").Funcs(template.FuncMap{
+	"decimal": decimal,
+	"signed":  signed,
+	"orDash":  orDash,
+	"diff":    func(it ConferenceItem) float64 { return it.Conferido - it.Esperado },
+	"datahora": func(c *Conference) string {
+		return c.GeradoEm.Format("02/01/2006 15:04")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>Conferência do romaneio {{.Fechamento}}</title>
+<style>
+  body { font-family: Helvetica, Arial, sans-serif; font-size: 12px; color: #111; margin: 24px; }
+  h1 { font-size: 20px; margin: 0; }
+  h2 { font-size: 14px; margin: 20px 0 6px; }
+  .topo { display: flex; justify-content: space-between; align-items: baseline; }
+  .situacao { font-weight: bold; margin: 4px 0 12px; }
+  .campos { display: grid; grid-template-columns: 1fr 1fr; gap: 4px 24px; border: 1px solid #999; padding: 10px; }
+  .campos b { display: inline-block; min-width: 110px; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { padding: 3px 6px; border-bottom: 1px solid #ddd; text-align: left; }
+  th { background: #e6e6e6; }
+  td.n, th.n { text-align: right; white-space: nowrap; }
+  tr.pendente td { color: #a00; }
+  tfoot td { font-weight: bold; border-top: 1px solid #999; }
+  .assinaturas { display: flex; gap: 40px; margin-top: 60px; }
+  .assinaturas div { flex: 1; border-top: 1px solid #000; padding-top: 4px; }
+  footer { margin-top: 24px; font-size: 10px; color: #666; }
+  @media print { body { margin: 0; } tr { page-break-inside: avoid; } }
+</style>
+</head>
+<body>
+<div class="topo">
+  <h1>Relatório de Conferência de Carga</h1>
+  <h1>Romaneio {{.Fechamento}}</h1>
+</div>
+<div class="situacao">{{.Situacao}} &middot; Conferência {{.NuUnico}}</div>
+
+<div class="campos">
+  <div><b>Data:</b> {{orDash .Data}}</div>
+  <div><b>Motorista:</b> {{orDash .Motorista}}</div>
+  <div><b>Placa:</b> {{orDash .Placa}}</div>
+  <div><b>Veículo:</b> {{orDash .Veiculo}}</div>
+  <div><b>Paletes:</b> {{decimal .Paletes}}</div>
+  <div><b>Peso total (kg):</b> {{decimal .PesoTotal}}</div>
+  <div><b>Conferente:</b> {{orDash .Operador}}</div>
+  <div><b>Progresso:</b> {{decimal .Percentual}}% das linhas</div>
+  <div><b>Início:</b> {{orDash .Inicio}}</div>
+  <div><b>Fim:</b> {{orDash .Fim}}</div>
+</div>
+
+<h2>Itens</h2>
+<table>
+  <thead>
+    <tr><th class="n">#</th><th>Produto</th><th>Descrição</th><th>Un</th><th class="n">Esperado</th><th class="n">Conferido</th><th class="n">Dif.</th><th>Conf.</th><th>Conferido em</th></tr>
+  </thead>
+  <tbody>
+  {{- range .Itens}}
+    <tr{{if not .Conferida}} class="pendente"{{end}}>
+      <td class="n">{{.NumReg}}</td><td>{{.Produto}}</td><td>{{.Descricao}}</td><td>{{.Unidade}}</td>
+      <td class="n">{{decimal .Esperado}}</td><td class="n">{{decimal .Conferido}}</td><td class="n">{{signed (diff .)}}</td>
+      <td>{{if .Conferida}}Sim{{else}}Não{{end}}</td><td>{{orDash .ConferidoEm}}</td>
+    </tr>
+  {{- end}}
+  </tbody>
+  <tfoot>
+    <tr>
+      <td colspan="3">Linhas: {{decimal .Linhas.Conferido}} de {{decimal .Linhas.Esperado}}</td>
+      <td colspan="3">Quantidade: {{decimal .Quantidade.Conferido}} de {{decimal .Quantidade.Esperado}}</td>
+      <td colspan="3">Peso: {{decimal .PesoBruto.Conferido}} de {{decimal .PesoBruto.Esperado}} kg</td>
+    </tr>
+  </tfoot>
+</table>
+
+<h2>Divergências</h2>
+{{- if .Divergencias}}
+<table>
+  <thead>
+    <tr><th>Tipo</th><th class="n">#</th><th>Produto</th><th>Descrição</th><th>Un</th><th class="n">Esperado</th><th class="n">Conferido</th><th class="n">Diferença</th></tr>
+  </thead>
+  <tbody>
+  {{- range .Divergencias}}
+    <tr>
+      <td>{{.Tipo}}</td><td class="n">{{.NumReg}}</td><td>{{.Produto}}</td><td>{{.Descricao}}</td><td>{{.Unidade}}</td>
+      <td class="n">{{decimal .Esperado}}</td><td class="n">{{decimal .Conferido}}</td><td class="n">{{signed .Diferenca}}</td>
+    </tr>
+  {{- end}}
+  </tbody>
+</table>
+{{- else}}
+<p>Nenhuma divergência.</p>
+{{- end}}
+
+{{- with .Observacoes}}
+<h2>Observações</h2>
+<ul>
+{{- range .}}
+  <li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+
+<div class="assinaturas"><div>Conferente</div><div>Motorista</div></div>
+<footer>Gerado em {{datahora .}}</footer>
+</body>
+</html>
+`))
+
+// RenderConferenceHTML escreve o relatório como página HTML pronta para impressão
+func RenderConferenceHTML(w io.Writer, c *Conference) error {
+	return conferenceHTML.Execute(w, c)
+}
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Dimensões A4 em pontos (1/72")
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+)
+
+// pdfDoc é um gerador mínimo de PDF 1.4: páginas A4, texto em Helvetica/Helvetica-Bold
+// (fontes padrão do leitor, WinAnsiEncoding), linhas e retângulos. Coordenadas com origem
+// no canto superior esquerdo, convertidas para o sistema do PDF na escrita.
+type pdfDoc struct {
+	title string
+	pages []*bytes.Buffer
+	cur   *bytes.Buffer
+}
+
+func newPDF(title string) *pdfDoc {
+	return &pdfDoc{title: title}
+}
+
+func (d *pdfDoc) addPage() {
+	d.cur = &bytes.Buffer{}
+	d.pages = append(d.pages, d.cur)
+}
+
+// text escreve s com a linha de base em y
+func (d *pdfDoc) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.cur, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, pageHeight-y, pdfString(s))
+}
+
+// textRight alinha o fim do texto em x
+func (d *pdfDoc) textRight(x, y, size float64, bold bool, s string) {
+	d.text(x-textWidth(s, size, bold), y, size, bold, s)
+}
+
+func (d *pdfDoc) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.cur, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, pageHeight-y1, x2, pageHeight-y2)
+}
+
+// fillRect pinta um retângulo em tom de cinza (0 = preto, 1 = branco)
+func (d *pdfDoc) fillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(d.cur, "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, pageHeight-y-h, w, h)
+}
+
+func (d *pdfDoc) strokeRect(x, y, w, h, width float64) {
+	fmt.Fprintf(d.cur, "%.2f w %.2f %.2f %.2f %.2f re S\n", width, x, pageHeight-y-h, w, h)
+}
+
+// WriteTo monta o arquivo: catálogo, árvore de páginas, fontes, páginas e xref
+func (d *pdfDoc) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+
+	var buf bytes.Buffer
+	offsets := []int{0} // Objeto 0 é o cabeçalho livre da xref
+	begin := func() int {
+		offsets = append(offsets, buf.Len())
+		n := len(offsets) - 1
+		fmt.Fprintf(&buf, "%d 0 obj\n", n)
+		return n
+	}
+	end := func() { buf.WriteString("endobj\n") }
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catálogo, 2 páginas, 3-4 fontes, 5 info; depois página + conteúdo, em pares
+	firstPage := 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+
+	begin()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\n")
+	end()
+
+	begin()
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(kids, " "), len(d.pages))
+	end()
+
+	for _, name := range []string{"Helvetica", "Helvetica-Bold"} {
+		begin()
+		fmt.Fprintf(&buf, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\n", name)
+		end()
+	}
+
+	begin()
+	fmt.Fprintf(&buf, "<< /Title (%s) /Producer (Zenith WMS) /CreationDate (D:%s) >>\n", pdfString(d.title), time.Now().Format("20060102150405"))
+	end()
+
+	for _, page := range d.pages {
+		pageObj := begin()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>\n",
+			pageWidth, pageHeight, pageObj+1)
+		end()
+
+		var content bytes.Buffer
+		zw := zlib.NewWriter(&content)
+		zw.Write(page.Bytes())
+		zw.Close()
+
+		begin()
+		fmt.Fprintf(&buf, "<< /Length %d /Filter /FlateDecode >>\nstream\n", content.Len())
+		buf.Write(content.Bytes())
+		buf.WriteString("\nendstream\n")
+		end()
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, off := range offsets[1:] {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// pdfString converte para WinAnsi (Latin-1 cobre o português) e escapa os delimitadores
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r >= 32 && r < 127:
+			b.WriteByte(byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r == '\t' || r == '\n' || r == '\r':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// Larguras AFM (1/1000 do tamanho) dos caracteres 32-126
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// widthFold aproxima a largura das letras acentuadas pela letra base
+var widthFold = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a", "é", "e", "ê", "e", "è", "e", "í", "i", "ì", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ò", "o", "ö", "o", "ú", "u", "ù", "u", "ü", "u", "ç", "c", "ñ", "n",
+	"Á", "A", "À", "A", "Â", "A", "Ã", "A", "Ä", "A", "É", "E", "Ê", "E", "È", "E", "Í", "I", "Ì", "I",
+	"Ó", "O", "Ô", "O", "Õ", "O", "Ò", "O", "Ö", "O", "Ú", "U", "Ù", "U", "Ü", "U", "Ç", "C", "Ñ", "N",
+	"º", "o", "ª", "a", "°", "o",
+)
+
+// textWidth mede o texto em pontos
+func textWidth(s string, size float64, bold bool) float64 {
+	widths := &helveticaWidths
+	if bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range widthFold.Replace(s) {
+		if r >= 32 && r <= 126 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// fitText corta o texto com "..." para caber na largura
+func fitText(s string, maxWidth, size float64, bold bool) string {
+	if textWidth(s, size, bold) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if cand := strings.TrimSpace(string(runes)) + "..."; textWidth(cand, size, bold) <= maxWidth {
+			return cand
+		}
+	}
+	return ""
+}
+
+// wrapWidth quebra o texto em linhas que cabem na largura
+func wrapWidth(s string, maxWidth, size float64, bold bool) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(s) {
+		cand := word
+		if current != "" {
+			cand = current + " " + word
+		}
+		if textWidth(cand, size, bold) <= maxWidth {
+			current = cand
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		current = fitText(word, maxWidth, size, bold)
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
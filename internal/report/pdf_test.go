@@ -0,0 +1,155 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPdfString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ABC 123", "ABC 123"},
+		{"(frágil)", `\(fr\341gil\)`},
+		{`C:\tmp`, `C:\\tmp`},
+		{"Conferência", `Confer\352ncia`},
+		{"AÇÚCAR ção", `A\307\332CAR \347\343o`},
+		{"ÿ ª", `\377 \252`},
+		{"linha\tum\ndois\r", "linha um dois "},
+		{"€ 10 → ok", "? 10 ? ok"},
+	}
+	for _, tt := range tests {
+		if got := pdfString(tt.in); got != tt.want {
+			t.Errorf("pdfString(%q) = %q, quer %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+var (
+	xrefEntryRegex = regexp.MustCompile(`^(\d{10}) (\d{5}) ([fn]) $`)
+	streamRegex    = regexp.MustCompile(`(?s)(\d+) 0 obj\n<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+)
+
+func TestRenderConferencePDF(t *testing.T) {
+	c := &Conference{
+		Fechamento: 4321,
+		NuUnico:    77,
+		Data:       "18/10/2026",
+		Motorista:  "JOÃO DA CONCEIÇÃO",
+		Placa:      "ABC1D23",
+		Operador:   "JOSÉ",
+		Observacao: "Carga (frágil) \\ conferir lacre",
+		GeradoEm:   time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local),
+	}
+	for i := 1; i <= 150; i++ {
+		c.Itens = append(c.Itens, ConferenceItem{
+			NumReg:    i,
+			Produto:   strconv.Itoa(5000 + i),
+			Descricao: fmt.Sprintf("AÇÚCAR REFINADO %d", i),
+			Unidade:   "CX",
+			Esperado:  10,
+			Conferido: 10,
+			Conferida: true,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := RenderConferencePDF(&buf, c); err != nil {
+		t.Fatalf("RenderConferencePDF: %v", err)
+	}
+	pdf := buf.Bytes()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("cabeçalho/rodapé do PDF inválidos")
+	}
+
+	// startxref aponta para a tabela xref
+	idx := bytes.LastIndex(pdf, []byte("startxref\n"))
+	if idx < 0 {
+		t.Fatal("startxref ausente")
+	}
+	xrefAt, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(string(pdf[idx+len("startxref\n"):]), "%%EOF\n")))
+	if err != nil {
+		t.Fatalf("startxref inválido: %v", err)
+	}
+	if !bytes.HasPrefix(pdf[xrefAt:], []byte("xref\n")) {
+		t.Fatalf("startxref (%d) não aponta para xref", xrefAt)
+	}
+
+	// Cada entrada da xref aponta para "N 0 obj"
+	lines := strings.Split(string(pdf[xrefAt:]), "\n")
+	var size int
+	if _, err := fmt.Sscanf(lines[1], "0 %d", &size); err != nil {
+		t.Fatalf("subseção da xref inválida: %q", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("entrada 0 da xref = %q", lines[2])
+	}
+	for n := 1; n < size; n++ {
+		m := xrefEntryRegex.FindStringSubmatch(lines[2+n])
+		if m == nil || m[3] != "n" {
+			t.Fatalf("entrada %d da xref inválida: %q", n, lines[2+n])
+		}
+		off, _ := strconv.Atoi(m[1])
+		if want := fmt.Sprintf("%d 0 obj\n", n); !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf("xref do objeto %d (offset %d) aponta para %q", n, off, pdf[off:min(off+12, len(pdf))])
+		}
+	}
+	if !strings.Contains(string(pdf[xrefAt:]), fmt.Sprintf("/Size %d ", size)) {
+		t.Errorf("trailer sem /Size %d", size)
+	}
+
+	// /Count bate com o número de páginas e com os objetos /Type /Page
+	pages := bytes.Count(pdf, []byte("/Type /Page /Parent 2 0 R"))
+	if pages < 3 {
+		t.Fatalf("esperava várias páginas para 150 itens, gerou %d", pages)
+	}
+	if !bytes.Contains(pdf, []byte(fmt.Sprintf("/Count %d >>", pages))) {
+		t.Errorf("/Count não corresponde às %d páginas", pages)
+	}
+	if size != 6+2*pages {
+		t.Errorf("xref com %d objetos, quer %d (5 fixos + página/conteúdo)", size, 6+2*pages)
+	}
+
+	// Conteúdo das páginas: /Length correto e texto em WinAnsi
+	var content strings.Builder
+	streams := streamRegex.FindAllSubmatchIndex(pdf, -1)
+	if len(streams) != pages {
+		t.Fatalf("%d streams de conteúdo, quer %d", len(streams), pages)
+	}
+	for _, s := range streams {
+		length, _ := strconv.Atoi(string(pdf[s[4]:s[5]]))
+		data := pdf[s[1] : s[1]+length]
+		if !bytes.HasPrefix(pdf[s[1]+length:], []byte("\nendstream\n")) {
+			t.Errorf("objeto %s: /Length %d não termina em endstream", pdf[s[2]:s[3]], length)
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("stream %s: %v", pdf[s[2]:s[3]], err)
+		}
+		text, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("stream %s: %v", pdf[s[2]:s[3]], err)
+		}
+		content.Write(text)
+	}
+
+	for _, want := range []string{
+		`(A\307\332CAR REFINADO 150)`,
+		`(JO\303O DA CONCEI\307\303O)`,
+		`(Carga \(fr\341gil\) \\ conferir lacre)`,
+		fmt.Sprintf(`(P\341gina %d de %d)`, pages, pages),
+	} {
+		if !strings.Contains(content.String(), want) {
+			t.Errorf("conteúdo sem %s", want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package sankhya
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"zenith-go/internal/gs1"
+)
+
+var onlyDigitsRegex = regexp.MustCompile(`^\d+$`)
+
+// ResolveBarcode identifica um código lido (EAN/DUN do produto ou etiqueta de endereço)
+// e devolve o produto, a unidade e os endereços do armazém que o contêm.
+// Código cadastrado em mais de um produto/unidade: BarcodeMatchError com ErrBarcodeAmbiguo
+// e os candidatos, até o coletor escolher com codProd/codVol.
+func (c *Client) ResolveBarcode(ctx context.Context, input BarcodeInput) (*BarcodeResult, error) {
+	codArm := input.CodArm
+	codigo := strings.TrimSpace(input.Codigo)
+	if codigo == "" {
+		return nil, ErrItemNotFound
+	}
+
+	// Etiquetas GS1-128/DataMatrix: busca pelo GTIN e devolve lote, validade e quantidade lidos
+	if gs1.IsGS1(codigo) {
+		if scan, err := gs1.Parse(codigo); err == nil && scan.GTIN != "" {
+			result, err := c.resolveProductBarcode(ctx, input, codigo, scan.GTINCandidates())
+			if err != nil {
+				return nil, err
+			}
+			result.Gs1 = newGs1Leitura(scan)
+			return result, nil
+		} else if err != nil {
+			slog.Debug("Leitura com aparência GS1 não decodificada, tentando como código simples", "codigo", codigo, "error", err)
+		}
+	}
+
+	result, err := c.resolveProductBarcode(ctx, input, codigo, []string{codigo})
+	if err == nil {
+		return result, nil
+	}
+	if !errors.Is(err, ErrItemNotFound) {
+		return nil, err
+	}
+
+	// Etiquetas de endereço carregam o SEQEND
+	if onlyDigitsRegex.MatchString(codigo) {
+		item, err := c.GetItemDetails(ctx, codArm, codigo)
+		if err != nil {
+			return nil, err
+		}
+
+		enderecos, err := c.searchItemsByProduct(ctx, codArm, item.CodProd)
+		if err != nil {
+			return nil, err
+		}
+
+		slog.Debug("Código resolvido como endereço", "codigo", codigo, "codArm", codArm)
+		return &BarcodeResult{
+			Codigo: codigo,
+			Tipo:   "ENDERECO",
+			Produto: &BarcodeProduto{
+				CodProd:   item.CodProd,
+				DescrProd: item.DescrProd,
+				Marca:     item.Marca,
+			},
+			Endereco:  item,
+			Enderecos: enderecos,
+		}, nil
+	}
+
+	return nil, ErrItemNotFound
+}
+
+// resolveProductBarcode procura os códigos candidatos em TGFVOA.CODBARRA
+func (c *Client) resolveProductBarcode(ctx context.Context, input BarcodeInput, codigo string, candidatos []string) (*BarcodeResult, error) {
+	var inList []string
+	for _, cand := range candidatos {
+		inList = append(inList, "'"+sanitizeStringForSql(cand)+"'")
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT VOA.CODPROD, 
+		       PRO.DESCRPROD, 
+		       PRO.MARCA, 
+		       VOA.CODVOL, 
+		       VOA.DESCRDANFE, 
+		       NVL(VOA.QUANTIDADE, 1) AS QUANTIDADE, 
+		       NVL(VOA.DIVIDEMULTIPLICA, 'M') AS DIVIDEMULTIPLICA, 
+		       PRO.CODVOL AS CODVOL_PADRAO
+		  FROM TGFVOA VOA 
+		  JOIN TGFPRO PRO ON PRO.CODPROD = VOA.CODPROD
+		 WHERE VOA.CODBARRA IN (%s)
+		 ORDER BY VOA.CODPROD, VOA.CODVOL`, strings.Join(inList, ", "))
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	// Os candidatos GS1 (GTIN-14/13/12) podem achar a mesma unidade mais de uma vez
+	var matches [][]any
+	var todos []BarcodeCandidato
+	seen := make(map[string]bool)
+	escolhaVol := strings.ToUpper(strings.TrimSpace(input.CodVol))
+	for _, row := range rows {
+		cand := BarcodeCandidato{
+			CodProd:   int(safeFloat64(row[0])),
+			DescrProd: safeString(row[1]),
+			Marca:     safeString(row[2]),
+			CodVol:    safeString(row[3]),
+			Descricao: safeString(row[4]),
+		}
+		key := conferenceUnitKey(cand.CodProd, cand.CodVol)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		todos = append(todos, cand)
+
+		if input.CodProd > 0 && cand.CodProd != input.CodProd {
+			continue
+		}
+		if escolhaVol != "" && strings.ToUpper(strings.TrimSpace(cand.CodVol)) != escolhaVol {
+			continue
+		}
+		matches = append(matches, row)
+	}
+	if len(matches) == 0 {
+		return nil, ErrItemNotFound
+	}
+	if len(matches) > 1 {
+		slog.Warn("Código de barras cadastrado em mais de uma unidade/produto", "codigo", codigo, "count", len(todos))
+		return nil, &BarcodeMatchError{Err: ErrBarcodeAmbiguo, Candidatos: todos}
+	}
+
+	row := matches[0]
+	codProd := int(safeFloat64(row[0]))
+	qtd := safeFloat64(row[5])
+	if qtd <= 0 {
+		qtd = 1
+	}
+	divMult := safeString(row[6])
+
+	fator := qtd
+	if divMult == "D" {
+		fator = 1 / qtd
+	}
+
+	enderecos, err := c.searchItemsByProduct(ctx, input.CodArm, codProd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BarcodeResult{
+		Codigo: codigo,
+		Tipo:   "PRODUTO",
+		Produto: &BarcodeProduto{
+			CodProd:   codProd,
+			DescrProd: safeString(row[1]),
+			Marca:     safeString(row[2]),
+		},
+		Unidade: &BarcodeUnidade{
+			CodVol:           safeString(row[3]),
+			Descricao:        safeString(row[4]),
+			Quantidade:       qtd,
+			DivideMultiplica: divMult,
+			UnidadePadrao:    safeString(row[7]),
+			FatorPadrao:      fator,
+		},
+		Enderecos: enderecos,
+	}, nil
+}
+
+// searchItemsByProduct lista os endereços do produto no armazém (picking primeiro, depois validade)
+func (c *Client) searchItemsByProduct(ctx context.Context, codArm int, codProd int) ([]SearchItemResult, error) {
+	sql := fmt.Sprintf(`%s
+		WHERE ENDE.CODARM = %d 
+		  AND ENDE.CODPROD = %d
+		ORDER BY ENDE.ENDPIC DESC, ENDE.DATVAL ASC`, searchItemsBaseSQL, codArm, codProd)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	results := mapSearchItemRows(rows)
+	if results == nil {
+		results = []SearchItemResult{}
+	}
+	return results, nil
+}
+
+// newGs1Leitura converte a leitura GS1 para o formato da API
+func newGs1Leitura(scan *gs1.Result) *Gs1Leitura {
+	leitura := &Gs1Leitura{
+		GTIN:        scan.GTIN,
+		SSCC:        scan.SSCC,
+		Lote:        scan.Lote,
+		Validade:    scan.ValidadeBR(),
+		Quantidade:  scan.Quantidade,
+		PesoLiquido: scan.PesoLiquido,
+	}
+	if !scan.Fabricacao.IsZero() {
+		leitura.Fabricacao = scan.Fabricacao.Format("02/01/2006")
+	}
+	return leitura
+}
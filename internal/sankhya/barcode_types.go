@@ -0,0 +1,66 @@
+package sankhya
+
+// BarcodeInput recebe o código lido pelo coletor
+type BarcodeInput struct {
+	CodArm  int    `json:"codArm"`
+	Codigo  string `json:"codigo"`
+	CodProd int    `json:"codProd"` // Opcional: escolhe o produto após um 409
+	CodVol  string `json:"codVol"`  // Opcional: escolhe a unidade após um 409
+}
+
+// BarcodeCandidato é um produto/unidade de TGFVOA com o código lido
+type BarcodeCandidato struct {
+	CodProd   int    `json:"codProd"`
+	DescrProd string `json:"descrProd"`
+	Marca     string `json:"marca"`
+	CodVol    string `json:"codVol"`
+	Descricao string `json:"descricao"`
+}
+
+// BarcodeMatchError carrega os candidatos quando o código não resolve para um único produto/unidade
+type BarcodeMatchError struct {
+	Err        error
+	Candidatos []BarcodeCandidato
+}
+
+func (e *BarcodeMatchError) Error() string { return e.Err.Error() }
+func (e *BarcodeMatchError) Unwrap() error { return e.Err }
+
+// BarcodeUnidade descreve a unidade (CODVOL) do código de barras e sua conversão para a unidade padrão
+type BarcodeUnidade struct {
+	CodVol           string  `json:"codVol"`
+	Descricao        string  `json:"descricao"`
+	Quantidade       float64 `json:"quantidade"`
+	DivideMultiplica string  `json:"divideMultiplica"` // M = multiplica, D = divide
+	UnidadePadrao    string  `json:"unidadePadrao"`
+	FatorPadrao      float64 `json:"fatorPadrao"` // Quantas unidades padrão equivalem a 1 CODVOL
+}
+
+// BarcodeProduto é o produto encontrado em TGFVOA.CODBARRA
+type BarcodeProduto struct {
+	CodProd   int    `json:"codProd"`
+	DescrProd string `json:"descrProd"`
+	Marca     string `json:"marca"`
+}
+
+// BarcodeResult é o resultado da resolução de um código lido
+type BarcodeResult struct {
+	Codigo    string             `json:"codigo"`
+	Tipo      string             `json:"tipo"` // PRODUTO ou ENDERECO
+	Produto   *BarcodeProduto    `json:"produto,omitempty"`
+	Unidade   *BarcodeUnidade    `json:"unidade,omitempty"`
+	Endereco  *ItemDetail        `json:"endereco,omitempty"`
+	Enderecos []SearchItemResult `json:"enderecos"`
+	Gs1       *Gs1Leitura        `json:"gs1,omitempty"`
+}
+
+// Gs1Leitura traz os dados decodificados de uma etiqueta GS1-128/DataMatrix
+type Gs1Leitura struct {
+	GTIN        string  `json:"gtin"`
+	SSCC        string  `json:"sscc,omitempty"`
+	Lote        string  `json:"lote,omitempty"`
+	Fabricacao  string  `json:"fabricacao,omitempty"`
+	Validade    string  `json:"validade,omitempty"` // DD/MM/YYYY, comparável ao DATVAL
+	Quantidade  float64 `json:"quantidade,omitempty"`
+	PesoLiquido float64 `json:"pesoLiquido,omitempty"`
+}
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -304,4 +305,13 @@ func (c *Client) ExecuteServiceAsSystem(ctx context.Context, serviceName string,
 
 func sanitizeStringForSql(s string) string {
 	return strings.ReplaceAll(s, "'", "")
+}
+
+// joinInts monta a lista de um IN (...) numérico
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
 }
\ No newline at end of file
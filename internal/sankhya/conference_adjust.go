@@ -0,0 +1,157 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// AjustarItemConferencia estorna ou corrige uma linha já conferida, enquanto a conferência
+// não foi finalizada. O ajuste passa pela mesma STP da conferência (regras do ERP); o estorno
+// não tem STP e limpa a linha direto em AD_ZNTITEMCONF. Toda alteração vai para AD_ZNTCONFLOG
+// com a quantidade anterior e a nova.
+func (c *Client) AjustarItemConferencia(ctx context.Context, input AjustarItemInput, codUsu int, snkSessionId string) (*AjustarItemResult, error) {
+	acao := strings.ToLower(strings.TrimSpace(input.Acao))
+	if acao != AjusteEstornar && acao != AjusteAjustar {
+		return nil, ErrConfAcaoInvalida
+	}
+	if acao == AjusteAjustar && input.QtdEmbarcada <= 0 {
+		return nil, ErrConfQuantidadeInvalida
+	}
+	motivo := strings.TrimSpace(input.Motivo)
+	if len([]rune(motivo)) < 10 {
+		return nil, ErrConfMotivo
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT NVL(CAB.CONFERIDO, 'N')
+		  FROM AD_ZNTCONFCAB CAB
+		 WHERE CAB.NUUNICO = %d`, input.NuUnico)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrConfNaoEncontrada
+	}
+	if safeString(rows[0][0]) == "S" {
+		return nil, ErrConfFinalizada
+	}
+
+	lines, err := c.GetConferenceLines(ctx, input.NuUnico)
+	if err != nil {
+		return nil, err
+	}
+	var linha *ConferenceLine
+	for i := range lines {
+		if lines[i].NumReg == input.NumReg {
+			linha = &lines[i]
+			break
+		}
+	}
+	if linha == nil {
+		return nil, ErrConfItemNaoEncontrado
+	}
+	if linha.Conferido != "S" {
+		return nil, ErrConfItemNaoConferido
+	}
+
+	result := &AjustarItemResult{Acao: acao, Linha: *linha, QtdAnterior: linha.QtdEmbarcada}
+	entry := ConferenceLogEntry{
+		NuUnico: input.NuUnico,
+		NumReg:  input.NumReg,
+		CodUsu:  codUsu,
+		Motivo:  motivo,
+		QtdAnt:  &result.QtdAnterior,
+	}
+
+	if acao == AjusteAjustar {
+		resp, err := c.ConferirItem(ctx, ConferirItemInput{NuUnico: input.NuUnico, NumReg: input.NumReg, QtdEmbarcada: input.QtdEmbarcada}, snkSessionId)
+		if err != nil {
+			return nil, err
+		}
+		result.QtdEmbarcada = input.QtdEmbarcada
+		result.Resposta = resp
+		entry.Evento = ConfEventoItemAjustado
+		entry.QtdNova = &result.QtdEmbarcada
+		entry.Detalhe = fmt.Sprintf("%d %s: %s -> %s", linha.CodProd, linha.Unidade, logQty(entry.QtdAnt), logQty(entry.QtdNova))
+	} else {
+		body := DatasetSaveBody{
+			EntityName: "AD_ZNTITEMCONF",
+			Fields:     []string{"CONFERIDO", "QTDEMBARCADA", "DTHCONF"},
+			Records: []DatasetRecord{{
+				PK: map[string]string{
+					"NUUNICO": fmt.Sprintf("%d", input.NuUnico),
+					"NUMREG":  fmt.Sprintf("%d", input.NumReg),
+				},
+				Values: map[string]string{"0": "N", "1": "", "2": ""},
+			}},
+		}
+		if _, err := c.ExecuteServiceAsSystem(ctx, "DatasetSP.save", body); err != nil {
+			return nil, fmt.Errorf("falha ao estornar item: %w", err)
+		}
+		entry.Evento = ConfEventoItemEstornado
+		entry.Detalhe = fmt.Sprintf("%d %s: %s -> não conferido", linha.CodProd, linha.Unidade, logQty(entry.QtdAnt))
+	}
+
+	slog.Info("Item da conferência alterado", "acao", acao, "nuUnico", input.NuUnico, "numReg", input.NumReg, "user", codUsu, "qtdAnt", result.QtdAnterior, "qtdNova", result.QtdEmbarcada)
+	// A linha já foi alterada no ERP: falha na auditoria só é registrada
+	if err := c.LogConferenceEvent(ctx, entry); err != nil {
+		slog.Error("Falha ao gravar auditoria da conferência", "nuUnico", input.NuUnico, "evento", entry.Evento, "error", err)
+	}
+	return result, nil
+}
+
+// GetConferenceItemHistory lista as alterações registradas para a linha, da mais antiga à mais recente
+func (c *Client) GetConferenceItemHistory(ctx context.Context, nuUnico, numReg int) ([]ConferenceHistoryEntry, error) {
+	sql := fmt.Sprintf(`
+		SELECT CLG.NUMLOG,
+		       CLG.EVENTO,
+		       TO_CHAR(CLG.DHEVENTO, 'DD/MM/YYYY HH24:MI:SS') AS DHEVENTO,
+		       CLG.CODUSU,
+		       USU.NOMEUSUCPLT,
+		       CLG.QTDANT,
+		       CLG.QTDNOVA,
+		       CLG.MOTIVO,
+		       CLG.DETALHE
+		  FROM AD_ZNTCONFLOG CLG
+		  LEFT JOIN TSIUSU USU ON USU.CODUSU = CLG.CODUSU
+		 WHERE CLG.NUUNICO = %d
+		   AND CLG.NUMREG = %d
+		 ORDER BY CLG.DHEVENTO, CLG.NUMLOG`, nuUnico, numReg)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]ConferenceHistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		history = append(history, ConferenceHistoryEntry{
+			NumLog:   int(safeFloat64(row[0])),
+			Evento:   safeString(row[1]),
+			DhEvento: safeString(row[2]),
+			CodUsu:   int(safeFloat64(row[3])),
+			NomeUsu:  safeString(row[4]),
+			QtdAnt:   nullableQty(row[5]),
+			QtdNova:  nullableQty(row[6]),
+			Motivo:   safeString(row[7]),
+			Detalhe:  safeString(row[8]),
+		})
+	}
+	return history, nil
+}
+
+// nullableQty preserva o NULL da auditoria (linha não conferida) em vez de virar 0
+func nullableQty(v any) *float64 {
+	if v == nil {
+		return nil
+	}
+	if s, ok := v.(string); ok && strings.TrimSpace(s) == "" {
+		return nil
+	}
+	q := safeFloat64(v)
+	return &q
+}
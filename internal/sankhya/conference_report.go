@@ -0,0 +1,68 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetConferenceReport monta o relatório da conferência de um fechamento: cabeçalho e itens
+// do romaneio-detalhe, horários e observações de AD_ZNTCONFCAB/AD_ZNTITEMCONF e o andamento
+// com as divergências
+func (c *Client) GetConferenceReport(ctx context.Context, nuFechamento int) (*ConferenceReport, error) {
+	detalhes, err := c.GetRomaneioDetalhes(ctx, nuFechamento)
+	if err != nil {
+		return nil, err
+	}
+
+	cabSQL := fmt.Sprintf(`
+		SELECT TO_CHAR(CAB.DTINICONF, 'DD/MM/YYYY HH24:MI'),
+		       TO_CHAR(CAB.DTFIMCONF, 'DD/MM/YYYY HH24:MI'),
+		       CAB.OBSFIM,
+		       NVL(CAB.CONFERIDO, 'N')
+		  FROM AD_ZNTCONFCAB CAB
+		 WHERE CAB.NUUNICO = %d`, detalhes.NuUnico)
+
+	cabRows, err := c.executeQuery(ctx, cabSQL)
+	if err != nil {
+		return nil, err
+	}
+	if len(cabRows) == 0 {
+		return nil, ErrConfNaoEncontrada
+	}
+
+	report := &ConferenceReport{
+		Romaneio:   detalhes,
+		Inicio:     safeString(cabRows[0][0]),
+		Fim:        safeString(cabRows[0][1]),
+		ObsFim:     safeString(cabRows[0][2]),
+		Finalizada: safeString(cabRows[0][3]) == "S",
+	}
+
+	itemSQL := fmt.Sprintf(`
+		SELECT ITC.NUMREG,
+		       ITC.OBS,
+		       TO_CHAR(ITC.DTHCONF, 'DD/MM/YYYY HH24:MI')
+		  FROM AD_ZNTITEMCONF ITC
+		 WHERE ITC.NUUNICO = %d`, detalhes.NuUnico)
+
+	itemRows, err := c.executeQuery(ctx, itemSQL)
+	if err != nil {
+		return nil, err
+	}
+	type itemExtra struct{ obs, em string }
+	extras := make(map[int]itemExtra, len(itemRows))
+	for _, row := range itemRows {
+		extras[int(safeFloat64(row[0]))] = itemExtra{obs: safeString(row[1]), em: safeString(row[2])}
+	}
+
+	report.Itens = make([]ConferenceReportItem, 0, len(detalhes.Produtos))
+	for _, p := range detalhes.Produtos {
+		extra := extras[p.NumReg]
+		report.Itens = append(report.Itens, ConferenceReportItem{RomaneioItem: p, Obs: extra.obs, ConferidoEm: extra.em})
+	}
+
+	if report.Progresso, err = c.GetConferenceProgress(ctx, detalhes.NuUnico); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,413 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"zenith-go/internal/gs1"
+)
+
+// GetConferenceLines lista as linhas da conferência (AD_ZNTITEMCONF) em ordem de NUMREG
+func (c *Client) GetConferenceLines(ctx context.Context, nuUnico int) ([]ConferenceLine, error) {
+	sql := fmt.Sprintf(`
+		SELECT CONF.NUMREG, 
+		       CONF.TIPO, 
+		       CONF.CODPROD, 
+		       NVL(CONF.DESCRPROD, PRO.DESCRPROD) AS DESCRPROD, 
+		       CONF.CODVOL, 
+		       NVL(CONF.QUANT, 0) AS QUANT, 
+		       NVL(CONF.CONFERIDO, 'N') AS CONFERIDO, 
+		       NVL(CONF.QTDEMBARCADA, 0) AS QTDEMBARCADA, 
+		       NVL(PRO.PESOBRUTO, 0) AS PESOBRUTO
+		  FROM AD_ZNTITEMCONF CONF 
+		  LEFT JOIN TGFPRO PRO ON PRO.CODPROD = CONF.CODPROD
+		 WHERE CONF.NUUNICO = %d
+		 ORDER BY CONF.NUMREG`, nuUnico)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]ConferenceLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, ConferenceLine{
+			NumReg:       int(safeFloat64(row[0])),
+			Tipo:         safeString(row[1]),
+			CodProd:      int(safeFloat64(row[2])),
+			Descricao:    safeString(row[3]),
+			Unidade:      safeString(row[4]),
+			Quantidade:   safeFloat64(row[5]),
+			Conferido:    safeString(row[6]),
+			QtdEmbarcada: safeFloat64(row[7]),
+			PesoUnitario: safeFloat64(row[8]),
+		})
+	}
+	return lines, nil
+}
+
+// ResolveConferenceCode encontra a linha da conferência correspondente a uma leitura.
+//
+// O código é procurado em TGFVOA (EAN/DUN por unidade, GTIN de etiqueta GS1 ou os 4 últimos
+// dígitos) apenas entre os produtos do romaneio; se nada bater e for numérico, vale como CODPROD.
+// Várias linhas do mesmo produto/unidade: usa a primeira ainda não conferida. Produtos ou
+// unidades diferentes: ConferenceMatchError com ErrConfCodigoAmbiguo e as candidatas.
+func (c *Client) ResolveConferenceCode(ctx context.Context, nuUnico int, codigo string, codVol string, numReg int) (*ConferenceLine, *gs1.Result, error) {
+	codigo = strings.TrimSpace(codigo)
+	if codigo == "" {
+		return nil, nil, ErrConfCodigoNaoEncontrado
+	}
+
+	lines, err := c.GetConferenceLines(ctx, nuUnico)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil, ErrConfNaoEncontrada
+	}
+
+	var scan *gs1.Result
+	candidatosCodigo := []string{codigo}
+	if gs1.IsGS1(codigo) {
+		if parsed, err := gs1.Parse(codigo); err == nil && parsed.GTIN != "" {
+			scan = parsed
+			candidatosCodigo = parsed.GTINCandidates()
+		} else if err != nil {
+			slog.Debug("Leitura com aparência GS1 não decodificada na conferência", "codigo", codigo, "error", err)
+		}
+	}
+
+	unidades, err := c.conferenceBarcodeUnits(ctx, lines, candidatosCodigo, scan == nil && len(codigo) == 4 && onlyDigitsRegex.MatchString(codigo))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var candidatas []ConferenceLine
+	for _, l := range lines {
+		if unidades[conferenceUnitKey(l.CodProd, l.Unidade)] {
+			candidatas = append(candidatas, l)
+		}
+	}
+
+	// Sem código de barras cadastrado: aceita o próprio CODPROD digitado
+	if len(candidatas) == 0 && scan == nil {
+		if codProd, err := strconv.Atoi(codigo); err == nil {
+			for _, l := range lines {
+				if l.CodProd == codProd {
+					candidatas = append(candidatas, l)
+				}
+			}
+		}
+	}
+
+	if codVol = strings.TrimSpace(codVol); codVol != "" {
+		candidatas = filterConferenceLines(candidatas, func(l ConferenceLine) bool { return strings.EqualFold(l.Unidade, codVol) })
+	}
+	if len(candidatas) == 0 {
+		return nil, scan, ErrConfCodigoNaoEncontrado
+	}
+
+	if numReg > 0 {
+		for _, l := range candidatas {
+			if l.NumReg == numReg {
+				return &l, scan, nil
+			}
+		}
+		return nil, scan, &ConferenceMatchError{Err: fmt.Errorf("%w (num_reg %d)", ErrConfCodigoNaoEncontrado, numReg), Candidatos: candidatas}
+	}
+
+	for _, l := range candidatas[1:] {
+		if l.CodProd != candidatas[0].CodProd || l.Unidade != candidatas[0].Unidade {
+			return nil, scan, &ConferenceMatchError{Err: ErrConfCodigoAmbiguo, Candidatos: candidatas}
+		}
+	}
+
+	for _, l := range candidatas {
+		if l.Conferido != "S" {
+			return &l, scan, nil
+		}
+	}
+	return nil, scan, &ConferenceMatchError{Err: ErrConfItemJaConferido, Candidatos: candidatas}
+}
+
+// conferenceBarcodeUnits busca em TGFVOA, só entre os produtos do romaneio, as unidades
+// (CODPROD|CODVOL) cujo código de barras bate com a leitura (ou termina nos 4 dígitos)
+func (c *Client) conferenceBarcodeUnits(ctx context.Context, lines []ConferenceLine, codigos []string, ultimos4 bool) (map[string]bool, error) {
+	var prods []int
+	seen := make(map[int]bool)
+	for _, l := range lines {
+		if l.CodProd > 0 && !seen[l.CodProd] {
+			seen[l.CodProd] = true
+			prods = append(prods, l.CodProd)
+		}
+	}
+	unidades := make(map[string]bool)
+	if len(prods) == 0 {
+		return unidades, nil
+	}
+
+	inList := make([]string, 0, len(codigos))
+	for _, cod := range codigos {
+		inList = append(inList, "'"+sanitizeStringForSql(cod)+"'")
+	}
+	filtro := fmt.Sprintf("VOA.CODBARRA IN (%s)", strings.Join(inList, ", "))
+	if ultimos4 {
+		filtro = fmt.Sprintf("(%s OR SUBSTR(VOA.CODBARRA, -4) = '%s')", filtro, sanitizeStringForSql(codigos[0]))
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT DISTINCT VOA.CODPROD, VOA.CODVOL
+		  FROM TGFVOA VOA
+		 WHERE VOA.CODPROD IN (%s)
+		   AND %s`, joinInts(prods), filtro)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		unidades[conferenceUnitKey(int(safeFloat64(row[0])), safeString(row[1]))] = true
+	}
+	return unidades, nil
+}
+
+// ConferirPorCodigo resolve a leitura para a linha do romaneio e chama a STP de conferência
+func (c *Client) ConferirPorCodigo(ctx context.Context, input ConferirPorCodigoInput, snkSessionId string) (*ConferirPorCodigoResult, error) {
+	linha, scan, err := c.ResolveConferenceCode(ctx, input.NuUnico, input.Codigo, input.CodVol, input.NumReg)
+	if err != nil {
+		return nil, err
+	}
+
+	qtd := input.QtdEmbarcada
+	if qtd <= 0 && scan != nil {
+		qtd = scan.Quantidade
+	}
+	if qtd <= 0 {
+		return nil, fmt.Errorf("%w: informe qtd_embarcada", ErrConfQuantidadeInvalida)
+	}
+
+	resp, err := c.ConferirItem(ctx, ConferirItemInput{
+		NuUnico:      input.NuUnico,
+		NumReg:       linha.NumReg,
+		QtdEmbarcada: qtd,
+		Obs:          input.Obs,
+	}, snkSessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Item conferido por leitura", "nuUnico", input.NuUnico, "numReg", linha.NumReg, "codProd", linha.CodProd, "codVol", linha.Unidade, "qtd", qtd)
+	return &ConferirPorCodigoResult{Linha: *linha, QtdEmbarcada: qtd, Resposta: resp}, nil
+}
+
+// GetConferenceProgress calcula o andamento e as divergências da conferência
+func (c *Client) GetConferenceProgress(ctx context.Context, nuUnico int) (*ConferenceProgress, error) {
+	sql := fmt.Sprintf(`
+		SELECT CAB.NUFECHAMENTO, 
+		       CAB.STATUS
+		  FROM AD_ZNTCONFCAB CAB
+		 WHERE CAB.NUUNICO = %d`, nuUnico)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrConfNaoEncontrada
+	}
+
+	lines, err := c.GetConferenceLines(ctx, nuUnico)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := computeConferenceProgress(lines)
+	progress.NuUnico = nuUnico
+	progress.Fechamento = int(safeFloat64(rows[0][0]))
+	progress.Status = safeString(rows[0][1])
+	return progress, nil
+}
+
+// computeConferenceProgress totaliza as linhas e classifica as divergências.
+// Quantidades são comparadas com 3 casas, como o peso bruto do romaneio.
+func computeConferenceProgress(lines []ConferenceLine) *ConferenceProgress {
+	p := &ConferenceProgress{Divergencias: []ConferenceDivergence{}}
+
+	for _, l := range lines {
+		conferido := l.Conferido == "S"
+
+		p.Linhas.Esperado++
+		p.Quantidade.Esperado += l.Quantidade
+		p.PesoBruto.Esperado += l.Quantidade * l.PesoUnitario
+		if conferido {
+			p.Linhas.Conferido++
+			p.Quantidade.Conferido += l.QtdEmbarcada
+			p.PesoBruto.Conferido += l.QtdEmbarcada * l.PesoUnitario
+		}
+
+		diferenca := roundQty(l.QtdEmbarcada - l.Quantidade)
+		tipo := ""
+		switch {
+		case !conferido:
+			tipo = DivergenciaNaoConferido
+			diferenca = roundQty(-l.Quantidade)
+		case diferenca < 0:
+			tipo = DivergenciaFalta
+		case diferenca > 0:
+			tipo = DivergenciaSobra
+		}
+		if tipo == "" {
+			continue
+		}
+
+		p.Divergencias = append(p.Divergencias, ConferenceDivergence{
+			NumReg:       l.NumReg,
+			Tipo:         tipo,
+			CodProd:      l.CodProd,
+			Descricao:    l.Descricao,
+			Unidade:      l.Unidade,
+			Quantidade:   l.Quantidade,
+			QtdEmbarcada: l.QtdEmbarcada,
+			Diferenca:    diferenca,
+		})
+	}
+
+	p.Quantidade.Esperado = roundQty(p.Quantidade.Esperado)
+	p.Quantidade.Conferido = roundQty(p.Quantidade.Conferido)
+	p.PesoBruto.Esperado = roundQty(p.PesoBruto.Esperado)
+	p.PesoBruto.Conferido = roundQty(p.PesoBruto.Conferido)
+	if p.Linhas.Esperado > 0 {
+		p.Percentual = math.Round(p.Linhas.Conferido/p.Linhas.Esperado*1000) / 10
+	}
+	p.SemPendencia = len(p.Divergencias) == 0
+	return p
+}
+
+// FinalizarConferenciaVerificada só finaliza quando não há linhas pendentes ou divergentes.
+// Com divergências, exige forcar + SUPCONF + justificativa: a justificativa vai para o OBSFIM
+// e para AD_ZNTCONFLOG.
+func (c *Client) FinalizarConferenciaVerificada(ctx context.Context, input FinalizarConferenciaInput, codUsu int, snkSessionId string) (*TransactionResponse, error) {
+	progress, err := c.GetConferenceProgress(ctx, input.NuUnico)
+	if err != nil {
+		return nil, err
+	}
+
+	forcada := !progress.SemPendencia
+	if forcada {
+		if !input.Forcar {
+			return nil, &ConferenceBlockedError{Progress: progress}
+		}
+
+		perms, err := c.GetUserPermissions(ctx, codUsu)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao verificar permissões: %w", err)
+		}
+		if !perms.SupConf {
+			return nil, ErrConfSupervisor
+		}
+
+		justificativa := strings.TrimSpace(input.Justificativa)
+		if len([]rune(justificativa)) < 10 {
+			return nil, ErrConfJustificativa
+		}
+
+		nota := fmt.Sprintf("FINALIZADO COM DIVERGÊNCIAS POR SUPERVISOR %d: %s", codUsu, justificativa)
+		if obs := strings.TrimSpace(input.ObsFim); obs != "" {
+			nota = obs + " | " + nota
+		}
+		input.ObsFim = nota
+	}
+
+	resp, err := c.FinalizarConferencia(ctx, input, snkSessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if forcada {
+		slog.Warn("Conferência finalizada com divergências", "nuUnico", input.NuUnico, "supervisor", codUsu, "divergencias", len(progress.Divergencias))
+		entry := ConferenceLogEntry{
+			NuUnico: input.NuUnico,
+			Evento:  ConfEventoFinalizacaoForcada,
+			CodUsu:  codUsu,
+			Motivo:  strings.TrimSpace(input.Justificativa),
+			Detalhe: divergenceSummary(progress.Divergencias),
+		}
+		// A finalização já aconteceu no ERP (e o OBSFIM tem a justificativa): falha aqui só é registrada
+		if err := c.LogConferenceEvent(ctx, entry); err != nil {
+			slog.Error("Falha ao gravar auditoria da conferência", "nuUnico", input.NuUnico, "evento", entry.Evento, "error", err)
+		}
+	}
+
+	return resp, nil
+}
+
+// LogConferenceEvent grava um evento na trilha de auditoria da conferência (AD_ZNTCONFLOG)
+func (c *Client) LogConferenceEvent(ctx context.Context, e ConferenceLogEntry) error {
+	numReg := ""
+	if e.NumReg > 0 {
+		numReg = strconv.Itoa(e.NumReg)
+	}
+
+	body := DatasetSaveBody{
+		EntityName: "AD_ZNTCONFLOG",
+		Fields:     []string{"NUUNICO", "NUMREG", "EVENTO", "CODUSU", "DHEVENTO", "MOTIVO", "DETALHE", "QTDANT", "QTDNOVA"},
+		Records: []DatasetRecord{{
+			Values: map[string]string{
+				"0": strconv.Itoa(e.NuUnico),
+				"1": numReg,
+				"2": e.Evento,
+				"3": strconv.Itoa(e.CodUsu),
+				"4": time.Now().Format("02/01/2006 15:04:05"),
+				"5": truncateRunes(e.Motivo, 400),
+				"6": truncateRunes(e.Detalhe, 4000),
+				"7": logQty(e.QtdAnt),
+				"8": logQty(e.QtdNova),
+			},
+		}},
+	}
+
+	_, err := c.ExecuteServiceAsSystem(ctx, "DatasetSP.save", body)
+	return err
+}
+
+// divergenceSummary resume as divergências em texto para a auditoria ("FALTA #4 (-2 CX); ...")
+func divergenceSummary(divs []ConferenceDivergence) string {
+	parts := make([]string, 0, len(divs))
+	for _, d := range divs {
+		parts = append(parts, fmt.Sprintf("%s #%d %d (%s %s)", d.Tipo, d.NumReg, d.CodProd, strconv.FormatFloat(d.Diferenca, 'f', -1, 64), d.Unidade))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// logQty formata a quantidade da auditoria; nil grava vazio (NULL)
+func logQty(q *float64) string {
+	if q == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*q, 'f', -1, 64)
+}
+
+func truncateRunes(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
+
+func conferenceUnitKey(codProd int, codVol string) string {
+	return fmt.Sprintf("%d|%s", codProd, strings.ToUpper(strings.TrimSpace(codVol)))
+}
+
+func filterConferenceLines(lines []ConferenceLine, keep func(ConferenceLine) bool) []ConferenceLine {
+	var result []ConferenceLine
+	for _, l := range lines {
+		if keep(l) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
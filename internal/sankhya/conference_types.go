@@ -0,0 +1,197 @@
+package sankhya
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrConfNaoEncontrada       = errors.New("conferência não encontrada ou sem itens")
+	ErrConfCodigoNaoEncontrado = errors.New("o código lido não pertence a nenhum item do romaneio")
+	ErrConfCodigoAmbiguo       = errors.New("o código lido corresponde a mais de um item do romaneio: informe num_reg ou cod_vol")
+	ErrConfItemJaConferido     = errors.New("todos os itens deste código já foram conferidos: informe num_reg para conferir novamente")
+	ErrConfQuantidadeInvalida  = errors.New("quantidade embarcada deve ser maior que zero")
+	ErrConfPendente            = errors.New("a conferência tem itens pendentes ou divergentes")
+	ErrConfSupervisor          = errors.New("somente supervisor de conferência (SUPCONF) pode finalizar com divergências")
+	ErrConfJustificativa       = errors.New("justificativa obrigatória (mínimo de 10 caracteres) para finalizar com divergências")
+	ErrConfFinalizada          = errors.New("a conferência já foi finalizada: os itens não podem mais ser alterados")
+	ErrConfItemNaoEncontrado   = errors.New("num_reg não pertence a esta conferência")
+	ErrConfItemNaoConferido    = errors.New("o item ainda não foi conferido: use conferir-item")
+	ErrConfAcaoInvalida        = errors.New("ação inválida: use 'estornar' ou 'ajustar'")
+	ErrConfMotivo              = errors.New("motivo obrigatório (mínimo de 10 caracteres) para estornar ou ajustar um item")
+)
+
+// ConferenceLine é uma linha de AD_ZNTITEMCONF, com o necessário para resolver uma leitura
+type ConferenceLine struct {
+	NumReg       int     `json:"num_reg"`
+	Tipo         string  `json:"tipo"`
+	CodProd      int     `json:"codigo_produto"`
+	Descricao    string  `json:"descricao"`
+	Unidade      string  `json:"unidade"` // CODVOL
+	Quantidade   float64 `json:"quantidade"`
+	Conferido    string  `json:"conferido"`
+	QtdEmbarcada float64 `json:"qtd_embarcada"`
+	PesoUnitario float64 `json:"-"` // TGFPRO.PESOBRUTO, para o peso conferido
+}
+
+// ConferirPorCodigoInput confere a partir de uma leitura (EAN/DUN, GS1 ou os 4 últimos dígitos)
+type ConferirPorCodigoInput struct {
+	NuUnico      int     `json:"nu_unico"`
+	Codigo       string  `json:"codigo"`
+	QtdEmbarcada float64 `json:"qtd_embarcada"` // 0 = usa a quantidade da etiqueta GS1 (AI 30/37)
+	NumReg       int     `json:"num_reg"`       // Opcional: escolhe a linha após um 409
+	CodVol       string  `json:"cod_vol"`       // Opcional: restringe a unidade
+	Obs          string  `json:"obs"`
+}
+
+// ConferirPorCodigoResult devolve a linha resolvida e a resposta da STP
+type ConferirPorCodigoResult struct {
+	Linha        ConferenceLine       `json:"linha"`
+	QtdEmbarcada float64              `json:"qtd_embarcada"`
+	Resposta     *TransactionResponse `json:"resposta"`
+}
+
+// ConferenceMatchError carrega as linhas candidatas quando a leitura não resolve para uma única linha
+type ConferenceMatchError struct {
+	Err        error
+	Candidatos []ConferenceLine
+}
+
+func (e *ConferenceMatchError) Error() string { return e.Err.Error() }
+func (e *ConferenceMatchError) Unwrap() error { return e.Err }
+
+// Tipos de divergência da conferência
+const (
+	DivergenciaFalta        = "FALTA"         // Conferido abaixo do esperado
+	DivergenciaSobra        = "SOBRA"         // Conferido acima do esperado
+	DivergenciaNaoConferido = "NAO_CONFERIDO" // Linha ainda não conferida
+)
+
+// ConferenceProgressInput identifica a conferência (AD_ZNTCONFCAB.NUUNICO)
+type ConferenceProgressInput struct {
+	NuUnico int `json:"nu_unico"`
+}
+
+// ConferenceTotals compara o esperado com o conferido
+type ConferenceTotals struct {
+	Esperado  float64 `json:"esperado"`
+	Conferido float64 `json:"conferido"`
+}
+
+// ConferenceDivergence é uma linha que impede a conferência de fechar sem ressalvas
+type ConferenceDivergence struct {
+	NumReg       int     `json:"num_reg"`
+	Tipo         string  `json:"tipo"` // FALTA | SOBRA | NAO_CONFERIDO
+	CodProd      int     `json:"codigo_produto"`
+	Descricao    string  `json:"descricao"`
+	Unidade      string  `json:"unidade"`
+	Quantidade   float64 `json:"quantidade"`
+	QtdEmbarcada float64 `json:"qtd_embarcada"`
+	Diferenca    float64 `json:"diferenca"` // Conferido - esperado
+}
+
+// ConferenceProgress é o andamento da conferência de um romaneio
+type ConferenceProgress struct {
+	NuUnico      int                    `json:"nu_unico"`
+	Fechamento   int                    `json:"fechamento"`
+	Status       string                 `json:"status_conf"`
+	Linhas       ConferenceTotals       `json:"linhas"`     // Esperado = total de linhas, Conferido = linhas conferidas
+	Quantidade   ConferenceTotals       `json:"quantidade"` // Soma das quantidades (unidades misturadas)
+	PesoBruto    ConferenceTotals       `json:"peso_bruto"`
+	Percentual   float64                `json:"percentual"` // Linhas conferidas / total
+	Divergencias []ConferenceDivergence `json:"divergencias"`
+	SemPendencia bool                   `json:"sem_pendencia"` // Nenhuma linha pendente ou divergente
+}
+
+// ConferenceBlockedError impede a finalização e devolve o andamento com as divergências
+type ConferenceBlockedError struct {
+	Progress *ConferenceProgress
+}
+
+func (e *ConferenceBlockedError) Error() string {
+	return fmt.Sprintf("%s (%d divergência(s))", ErrConfPendente.Error(), len(e.Progress.Divergencias))
+}
+func (e *ConferenceBlockedError) Unwrap() error { return ErrConfPendente }
+
+// Eventos gravados em AD_ZNTCONFLOG
+const (
+	ConfEventoFinalizacaoForcada = "FINALIZACAO_FORCADA"
+	ConfEventoPosseTransferida   = "POSSE_TRANSFERIDA" // Supervisor passou a conferência para outro operador
+	ConfEventoPosseLiberada      = "POSSE_LIBERADA"    // Supervisor liberou a conferência
+	ConfEventoItemConferido      = "ITEM_CONFERIDO"    // Linha conferida (ou conferida de novo)
+	ConfEventoItemEstornado      = "ITEM_ESTORNADO"    // Linha voltou para não conferida
+	ConfEventoItemAjustado       = "ITEM_AJUSTADO"     // Quantidade embarcada corrigida
+)
+
+// ConferenceLogEntry é um registro da trilha de auditoria da conferência (AD_ZNTCONFLOG)
+type ConferenceLogEntry struct {
+	NuUnico int
+	NumReg  int // 0 = evento do cabeçalho
+	Evento  string
+	CodUsu  int
+	Motivo  string
+	Detalhe string
+	QtdAnt  *float64 // Eventos de linha: quantidade embarcada antes (nil = não conferida / desconhecida)
+	QtdNova *float64 // Eventos de linha: quantidade embarcada depois (nil = não conferida)
+}
+
+// Ações de AjustarItemInput
+const (
+	AjusteEstornar = "estornar" // Volta a linha para não conferida
+	AjusteAjustar  = "ajustar"  // Corrige a quantidade embarcada
+)
+
+// AjustarItemInput estorna ou corrige uma linha já conferida
+type AjustarItemInput struct {
+	NuUnico      int     `json:"nu_unico"`
+	NumReg       int     `json:"num_reg"`
+	Acao         string  `json:"acao"`          // estornar | ajustar
+	QtdEmbarcada float64 `json:"qtd_embarcada"` // Só no ajuste
+	Motivo       string  `json:"motivo"`
+}
+
+// AjustarItemResult devolve a linha antes da alteração e a quantidade que ficou
+type AjustarItemResult struct {
+	Acao         string               `json:"acao"`
+	Linha        ConferenceLine       `json:"linha"` // Como estava antes
+	QtdAnterior  float64              `json:"qtd_anterior"`
+	QtdEmbarcada float64              `json:"qtd_embarcada"` // 0 no estorno
+	Resposta     *TransactionResponse `json:"resposta,omitempty"`
+}
+
+// ConferenceItemHistoryInput identifica a linha (NUUNICO + NUMREG)
+type ConferenceItemHistoryInput struct {
+	NuUnico int `json:"nu_unico"`
+	NumReg  int `json:"num_reg"`
+}
+
+// ConferenceHistoryEntry é uma alteração registrada para a linha em AD_ZNTCONFLOG
+type ConferenceHistoryEntry struct {
+	NumLog   int      `json:"num_log"`
+	Evento   string   `json:"evento"`
+	DhEvento string   `json:"dh_evento"` // DD/MM/YYYY HH24:MI:SS
+	CodUsu   int      `json:"cod_usu"`
+	NomeUsu  string   `json:"nome_usu"`
+	QtdAnt   *float64 `json:"qtd_ant"`
+	QtdNova  *float64 `json:"qtd_nova"`
+	Motivo   string   `json:"motivo"`
+	Detalhe  string   `json:"detalhe"`
+}
+
+// ConferenceReport reúne os dados do relatório de conferência de um fechamento
+type ConferenceReport struct {
+	Romaneio   *RomaneioDetalheResponse
+	Inicio     string // DTINICONF (DD/MM/YYYY HH24:MI)
+	Fim        string // DTFIMCONF, vazio enquanto não finalizada
+	ObsFim     string
+	Finalizada bool // CONFERIDO = 'S'
+	Itens      []ConferenceReportItem
+	Progresso  *ConferenceProgress
+}
+
+// ConferenceReportItem é a linha do romaneio com a observação e o horário da conferência
+type ConferenceReportItem struct {
+	RomaneioItem
+	Obs         string
+	ConferidoEm string
+}
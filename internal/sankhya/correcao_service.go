@@ -0,0 +1,221 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// createCorrecaoPendente registra a solicitação em AD_CORRPEND e retorna o NUCORR gerado
+func (c *Client) createCorrecaoPendente(ctx context.Context, item *correcaoItem, newQuantity float64, codUsu int) (string, error) {
+	body := DatasetSaveBody{
+		EntityName: "AD_CORRPEND",
+		Fields:     []string{"NUCORR", "CODARM", "SEQEND", "CODPROD", "CODVOL", "QTDANT", "QTDNOVA", "CODUSU", "DHSOLIC", "STATUS"},
+		Records: []DatasetRecord{{
+			Values: map[string]string{
+				"1": strconv.Itoa(item.CodArm),
+				"2": strconv.Itoa(item.SeqEnd),
+				"3": item.CodProd,
+				"4": item.CodVol,
+				"5": fmt.Sprintf("%.3f", item.QtdAnt),
+				"6": fmt.Sprintf("%.3f", newQuantity),
+				"7": strconv.Itoa(codUsu),
+				"8": time.Now().Format("02/01/2006 15:04:05"),
+				"9": "P",
+			},
+		}},
+	}
+
+	res, err := c.ExecuteServiceAsSystem(ctx, "DatasetSP.save", body)
+	if err != nil {
+		return "", err
+	}
+	if len(res.ResponseBody.Result) == 0 || len(res.ResponseBody.Result[0]) == 0 {
+		return "", fmt.Errorf("não retornou NUCORR")
+	}
+	return res.ResponseBody.Result[0][0], nil
+}
+
+// findCorrecaoPendente retorna o NUCORR de uma solicitação ainda pendente para o endereço (0 se não houver)
+func (c *Client) findCorrecaoPendente(ctx context.Context, codArm int, seqEnd int) (int, error) {
+	sql := fmt.Sprintf(`
+		SELECT MIN(NUCORR)
+		  FROM AD_CORRPEND
+		 WHERE CODARM = %d AND SEQEND = %d AND STATUS = 'P'`, codArm, seqEnd)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return int(safeFloat64(rows[0][0])), nil
+}
+
+// ListCorrecoesPendentes retorna as correções aguardando aprovação nos armazéns informados (mais antigas primeiro)
+func (c *Client) ListCorrecoesPendentes(ctx context.Context, armazens []int) ([]CorrecaoPendente, error) {
+	if len(armazens) == 0 {
+		return []CorrecaoPendente{}, nil
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT C.NUCORR, 
+		       C.CODARM, 
+		       C.SEQEND, 
+		       C.CODPROD, 
+		       PRO.DESCRPROD, 
+		       PRO.MARCA, 
+		       C.CODVOL, 
+		       C.QTDANT, 
+		       C.QTDNOVA, 
+		       C.CODUSU, 
+		       USU.NOMEUSUCPLT, 
+		       TO_CHAR(C.DHSOLIC, 'DD/MM/YYYY HH24:MI:SS') AS DHSOLIC
+		  FROM AD_CORRPEND C
+		  LEFT JOIN TGFPRO PRO ON PRO.CODPROD = C.CODPROD
+		  LEFT JOIN TSIUSU USU ON USU.CODUSU = C.CODUSU
+		 WHERE C.STATUS = 'P'
+		   AND C.CODARM IN (%s)
+		 ORDER BY C.DHSOLIC ASC`, joinInts(armazens))
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []CorrecaoPendente{}
+	for _, row := range rows {
+		qtdAnt := safeFloat64(row[7])
+		qtdNova := safeFloat64(row[8])
+		results = append(results, CorrecaoPendente{
+			NuCorr:    int(safeFloat64(row[0])),
+			CodArm:    int(safeFloat64(row[1])),
+			SeqEnd:    int(safeFloat64(row[2])),
+			CodProd:   int(safeFloat64(row[3])),
+			DescrProd: safeString(row[4]),
+			Marca:     safeString(row[5]),
+			CodVol:    safeString(row[6]),
+			QtdAnt:    qtdAnt,
+			QtdNova:   qtdNova,
+			Diferenca: qtdNova - qtdAnt,
+			CodUsu:    int(safeFloat64(row[9])),
+			NomeUsu:   safeString(row[10]),
+			DhSolic:   safeString(row[11]),
+		})
+	}
+
+	return results, nil
+}
+
+// getCorrecaoPendente busca uma solicitação ainda não processada
+func (c *Client) getCorrecaoPendente(ctx context.Context, nuCorr int) (*CorrecaoPendente, error) {
+	sql := fmt.Sprintf(`
+		SELECT NUCORR, CODARM, SEQEND, QTDANT, QTDNOVA, CODUSU, CODPROD, CODVOL
+		  FROM AD_CORRPEND 
+		 WHERE NUCORR = %d AND STATUS = 'P'`, nuCorr)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrCorrecaoNaoEncontrada
+	}
+
+	row := rows[0]
+	return &CorrecaoPendente{
+		NuCorr:  int(safeFloat64(row[0])),
+		CodArm:  int(safeFloat64(row[1])),
+		SeqEnd:  int(safeFloat64(row[2])),
+		QtdAnt:  safeFloat64(row[3]),
+		QtdNova: safeFloat64(row[4]),
+		CodUsu:  int(safeFloat64(row[5])),
+		CodProd: int(safeFloat64(row[6])),
+		CodVol:  safeString(row[7]),
+	}, nil
+}
+
+// AprovarCorrecao aplica a correção pendente com a sessão do supervisor e registra o aprovador.
+// Quem chama deve serializar por NUCORR (ver handler): leitura, aplicação e status são chamadas separadas.
+func (c *Client) AprovarCorrecao(ctx context.Context, nuCorr int, codUsuAprov int, snkSessionId string) (string, error) {
+	pend, err := c.getCorrecaoPendente(ctx, nuCorr)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := c.AuthorizeWarehouses(ctx, codUsuAprov, pend.CodArm); err != nil {
+		return "", err
+	}
+
+	item, err := c.getCorrecaoItem(ctx, pend.CodArm, pend.SeqEnd)
+	if err != nil {
+		return "", err
+	}
+
+	// Endereço esvaziado e reabastecido com outro produto/unidade: a correção não vale mais
+	codProdAtual, _ := strconv.ParseFloat(strings.TrimSpace(item.CodProd), 64)
+	if int(codProdAtual) != pend.CodProd || !strings.EqualFold(strings.TrimSpace(item.CodVol), strings.TrimSpace(pend.CodVol)) {
+		slog.Warn("Produto/unidade do endereço alterado desde a solicitação de correção", "nucorr", nuCorr,
+			"codProdSolic", pend.CodProd, "codVolSolic", pend.CodVol, "codProdAtual", item.CodProd, "codVolAtual", item.CodVol)
+		return "", ErrCorrecaoDesatualizada
+	}
+
+	// Se o saldo mudou desde a solicitação, a diferença aprovada não é mais a mesma
+	if math.Abs(item.QtdAnt-pend.QtdAnt) > 0.0005 {
+		slog.Warn("Saldo alterado desde a solicitação de correção", "nucorr", nuCorr, "qtdSolic", pend.QtdAnt, "qtdAtual", item.QtdAnt)
+		return "", ErrCorrecaoDesatualizada
+	}
+
+	slog.Info("Aprovando correção de estoque", "nucorr", nuCorr, "aprovador", codUsuAprov, "solicitante", pend.CodUsu)
+	if err := c.applyCorrecao(ctx, item, pend.QtdNova, pend.CodUsu, codUsuAprov, snkSessionId); err != nil {
+		return "", err
+	}
+
+	if err := c.updateCorrecaoStatus(ctx, nuCorr, "A", codUsuAprov, ""); err != nil {
+		// O estoque já foi corrigido e a solicitação continua pendente. Uma nova aprovação cai
+		// em ErrCorrecaoDesatualizada (o saldo já é outro), então ela precisa ser rejeitada
+		slog.Error("Erro ao atualizar status da correção aprovada", "nucorr", nuCorr, "error", err)
+		return "", fmt.Errorf("%w: %v", ErrCorrecaoStatus, err)
+	}
+
+	return "Correção aprovada e aplicada com sucesso!", nil
+}
+
+// RejeitarCorrecao encerra a solicitação sem alterar o estoque
+func (c *Client) RejeitarCorrecao(ctx context.Context, nuCorr int, codUsuAprov int, motivo string) error {
+	pend, err := c.getCorrecaoPendente(ctx, nuCorr)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.AuthorizeWarehouses(ctx, codUsuAprov, pend.CodArm); err != nil {
+		return err
+	}
+
+	slog.Info("Rejeitando correção de estoque", "nucorr", nuCorr, "aprovador", codUsuAprov)
+	return c.updateCorrecaoStatus(ctx, nuCorr, "R", codUsuAprov, motivo)
+}
+
+func (c *Client) updateCorrecaoStatus(ctx context.Context, nuCorr int, status string, codUsuAprov int, motivo string) error {
+	body := DatasetSaveBody{
+		EntityName: "AD_CORRPEND",
+		Fields:     []string{"STATUS", "CODUSUAPR", "DHAPROV", "MOTIVO"},
+		Records: []DatasetRecord{{
+			PK: map[string]string{"NUCORR": strconv.Itoa(nuCorr)},
+			Values: map[string]string{
+				"0": status,
+				"1": strconv.Itoa(codUsuAprov),
+				"2": time.Now().Format("02/01/2006 15:04:05"),
+				"3": motivo,
+			},
+		}},
+	}
+
+	_, err := c.ExecuteServiceAsSystem(ctx, "DatasetSP.save", body)
+	return err
+}
@@ -0,0 +1,29 @@
+package sankhya
+
+// CorrecaoPendente representa uma correção acima do limite aguardando o supervisor
+type CorrecaoPendente struct {
+	NuCorr    int     `json:"nuCorr"`
+	CodArm    int     `json:"codArm"`
+	SeqEnd    int     `json:"seqEnd"`
+	CodProd   int     `json:"codProd"`
+	DescrProd string  `json:"descrProd"`
+	Marca     string  `json:"marca"`
+	CodVol    string  `json:"codVol"`
+	QtdAnt    float64 `json:"qtdAnt"`
+	QtdNova   float64 `json:"qtdNova"`
+	Diferenca float64 `json:"diferenca"`
+	CodUsu    int     `json:"codUsu"`
+	NomeUsu   string  `json:"nomeUsu"`
+	DhSolic   string  `json:"dhSolic"`
+}
+
+// AprovarCorrecaoInput identifica a solicitação a ser aprovada
+type AprovarCorrecaoInput struct {
+	NuCorr int `json:"nuCorr"`
+}
+
+// RejeitarCorrecaoInput identifica a solicitação e o motivo da rejeição
+type RejeitarCorrecaoInput struct {
+	NuCorr int    `json:"nuCorr"`
+	Motivo string `json:"motivo"`
+}
@@ -0,0 +1,65 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// CheckErpActions confirma que os botões de ação (TSIBTA) e as procedures mapeadas existem no ERP
+func (c *Client) CheckErpActions(ctx context.Context) error {
+	actions := c.cfg.ErpActions.All()
+
+	ids := []string{}
+	procs := []string{}
+	for _, a := range actions {
+		ids = append(ids, a.ActionID)
+		if a.ProcName != "" {
+			procs = append(procs, "'"+sanitizeStringForSql(a.ProcName)+"'")
+		}
+	}
+
+	rows, err := c.executeQuery(ctx, fmt.Sprintf(`SELECT IDBTNACAO FROM TSIBTA WHERE IDBTNACAO IN (%s)`, strings.Join(ids, ", ")))
+	if err != nil {
+		return fmt.Errorf("erro ao consultar botões de ação: %w", err)
+	}
+	foundIDs := map[string]bool{}
+	for _, row := range rows {
+		foundIDs[fmt.Sprintf("%.0f", safeFloat64(row[0]))] = true
+	}
+
+	foundProcs := map[string]bool{}
+	if len(procs) > 0 {
+		rows, err = c.executeQuery(ctx, fmt.Sprintf(`
+			SELECT DISTINCT OBJECT_NAME 
+			  FROM ALL_OBJECTS 
+			 WHERE OBJECT_TYPE = 'PROCEDURE' 
+			   AND OBJECT_NAME IN (%s)`, strings.Join(procs, ", ")))
+		if err != nil {
+			return fmt.Errorf("erro ao consultar procedures: %w", err)
+		}
+		for _, row := range rows {
+			foundProcs[safeString(row[0])] = true
+		}
+	}
+
+	var missing []string
+	for name, a := range actions {
+		if !foundIDs[a.ActionID] {
+			missing = append(missing, fmt.Sprintf("%s: ação %s inexistente", name, a.ActionID))
+		}
+		if a.ProcName != "" && !foundProcs[a.ProcName] {
+			missing = append(missing, fmt.Sprintf("%s: procedure %s inexistente", name, a.ProcName))
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("mapeamento de ações não confere com o ERP (ambiente '%s'): %s", c.cfg.SankhyaEnv, strings.Join(missing, "; "))
+	}
+
+	slog.Info("Mapeamento de ações do ERP verificado", "ambiente", c.cfg.SankhyaEnv, "acoes", len(actions))
+	return nil
+}
@@ -0,0 +1,159 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+const expiryDefaultDias = 30
+
+// GetExpiryReport lista os endereços com saldo cuja validade vence em até f.Dias dias
+// (vencidos incluídos), restrito aos armazéns informados
+func (c *Client) GetExpiryReport(ctx context.Context, armazens []int, f ExpiryReportFilter) (*ExpiryReport, error) {
+	dias := f.Dias
+	if dias <= 0 {
+		dias = expiryDefaultDias
+	}
+
+	report := &ExpiryReport{
+		Dias:       dias,
+		Itens:      []ExpiryItem{},
+		PorArmazem: []ExpiryGroup{},
+		PorProduto: []ExpiryGroup{},
+		PorMarca:   []ExpiryGroup{},
+	}
+	if len(armazens) == 0 {
+		return report, nil
+	}
+
+	where := []string{
+		fmt.Sprintf("ENDE.CODARM IN (%s)", joinInts(armazens)),
+		"ENDE.DATVAL IS NOT NULL",
+		"NVL(ENDE.QTDPRO, 0) > 0",
+		fmt.Sprintf("TRUNC(ENDE.DATVAL) <= TRUNC(SYSDATE) + %d", dias),
+	}
+	if f.CodArm > 0 {
+		where = append(where, fmt.Sprintf("ENDE.CODARM = %d", f.CodArm))
+	}
+	if f.CodProd > 0 {
+		where = append(where, fmt.Sprintf("ENDE.CODPROD = %d", f.CodProd))
+	}
+	if marca := strings.TrimSpace(f.Marca); marca != "" {
+		where = append(where, fmt.Sprintf("UPPER(PRO.MARCA) = '%s'", sanitizeStringForSql(strings.ToUpper(marca))))
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT ENDE.CODARM, 
+		       ARM.DESARM, 
+		       ENDE.SEQEND, 
+		       ENDE.CODRUA, 
+		       ENDE.CODPRD, 
+		       ENDE.CODAPT, 
+		       ENDE.CODPROD, 
+		       PRO.DESCRPROD, 
+		       PRO.MARCA, 
+		       TO_CHAR(ENDE.DATVAL, 'DD/MM/YYYY') AS DATVAL, 
+		       TRUNC(ENDE.DATVAL) - TRUNC(SYSDATE) AS DIAS_RESTANTES, 
+		       ENDE.QTDPRO, 
+		       ENDE.CODVOL, 
+		       ENDE.ENDPIC
+		  FROM AD_CADEND ENDE 
+		  JOIN TGFPRO PRO ON PRO.CODPROD = ENDE.CODPROD
+		  JOIN AD_CADARM ARM ON ARM.CODARM = ENDE.CODARM
+		 WHERE %s
+		 ORDER BY ENDE.DATVAL ASC, ENDE.CODARM, ENDE.SEQEND`, strings.Join(where, "\n		   AND "))
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	porArmazem := newExpiryGroups()
+	porProduto := newExpiryGroups()
+	porMarca := newExpiryGroups()
+
+	for _, row := range rows {
+		item := ExpiryItem{
+			CodArm:        int(safeFloat64(row[0])),
+			DesArm:        safeString(row[1]),
+			SeqEnd:        int(safeFloat64(row[2])),
+			CodRua:        safeString(row[3]),
+			CodPrd:        int(safeFloat64(row[4])),
+			CodApt:        safeString(row[5]),
+			CodProd:       int(safeFloat64(row[6])),
+			DescrProd:     safeString(row[7]),
+			Marca:         safeString(row[8]),
+			DatVal:        safeString(row[9]),
+			DiasRestantes: int(safeFloat64(row[10])),
+			QtdPro:        safeFloat64(row[11]),
+			CodVol:        safeString(row[12]),
+			EndPic:        safeString(row[13]),
+		}
+		report.Itens = append(report.Itens, item)
+
+		// Como as linhas vêm por validade, o primeiro item de cada grupo é o mais próximo
+		porArmazem.add(strconv.Itoa(item.CodArm), item.DesArm, item)
+		porProduto.add(strconv.Itoa(item.CodProd), item.DescrProd, item)
+		porMarca.add(item.Marca, item.Marca, item)
+	}
+
+	report.PorArmazem = porArmazem.list()
+	report.PorProduto = porProduto.list()
+	report.PorMarca = porMarca.list()
+
+	slog.Debug("Relatório de validade gerado", "dias", dias, "enderecos", len(report.Itens))
+	return report, nil
+}
+
+// ListWarehouseCodes retorna todos os armazéns cadastrados em AD_CADARM (usado pelos jobs de sistema)
+func (c *Client) ListWarehouseCodes(ctx context.Context) ([]int, error) {
+	rows, err := c.executeQuery(ctx, `SELECT CODARM FROM AD_CADARM ORDER BY CODARM`)
+	if err != nil {
+		return nil, err
+	}
+
+	codArms := make([]int, 0, len(rows))
+	for _, row := range rows {
+		codArms = append(codArms, int(safeFloat64(row[0])))
+	}
+	return codArms, nil
+}
+
+// expiryGroups acumula ExpiryGroup preservando a ordem de aparição
+type expiryGroups struct {
+	byKey map[string]*ExpiryGroup
+	order []string
+}
+
+func newExpiryGroups() *expiryGroups {
+	return &expiryGroups{byKey: make(map[string]*ExpiryGroup)}
+}
+
+func (g *expiryGroups) add(chave string, descricao string, item ExpiryItem) {
+	group, ok := g.byKey[chave]
+	if !ok {
+		group = &ExpiryGroup{
+			Chave:              chave,
+			Descricao:          descricao,
+			ProximaValidade:    item.DatVal,
+			MenorDiasRestantes: item.DiasRestantes,
+		}
+		g.byKey[chave] = group
+		g.order = append(g.order, chave)
+	}
+	group.Enderecos++
+	if item.DiasRestantes < 0 {
+		group.Vencidos++
+	}
+}
+
+func (g *expiryGroups) list() []ExpiryGroup {
+	list := make([]ExpiryGroup, 0, len(g.order))
+	for _, chave := range g.order {
+		list = append(list, *g.byKey[chave])
+	}
+	return list
+}
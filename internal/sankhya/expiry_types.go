@@ -0,0 +1,46 @@
+package sankhya
+
+// ExpiryReportFilter filtra o relatório de validade (Dias = horizonte a partir de hoje)
+type ExpiryReportFilter struct {
+	Dias    int    `json:"dias"`
+	CodArm  int    `json:"codArm"`
+	CodProd int    `json:"codProd"`
+	Marca   string `json:"marca"`
+}
+
+// ExpiryItem é um endereço com saldo vencido ou a vencer no horizonte
+type ExpiryItem struct {
+	CodArm        int     `json:"codArm"`
+	DesArm        string  `json:"desArm"`
+	SeqEnd        int     `json:"seqEnd"`
+	CodRua        string  `json:"codRua"`
+	CodPrd        int     `json:"codPrd"`
+	CodApt        string  `json:"codApt"`
+	CodProd       int     `json:"codProd"`
+	DescrProd     string  `json:"descrProd"`
+	Marca         string  `json:"marca"`
+	DatVal        string  `json:"datVal"`
+	DiasRestantes int     `json:"diasRestantes"` // Negativo = vencido há N dias
+	QtdPro        float64 `json:"qtdPro"`
+	CodVol        string  `json:"codVol"`
+	EndPic        string  `json:"endPic"`
+}
+
+// ExpiryGroup resume o relatório por armazém, produto ou marca
+type ExpiryGroup struct {
+	Chave              string `json:"chave"`
+	Descricao          string `json:"descricao"`
+	Enderecos          int    `json:"enderecos"`
+	Vencidos           int    `json:"vencidos"`
+	ProximaValidade    string `json:"proximaValidade"`
+	MenorDiasRestantes int    `json:"menorDiasRestantes"`
+}
+
+// ExpiryReport é o relatório completo, ordenado da validade mais próxima
+type ExpiryReport struct {
+	Dias       int           `json:"dias"`
+	Itens      []ExpiryItem  `json:"itens"`
+	PorArmazem []ExpiryGroup `json:"porArmazem"`
+	PorProduto []ExpiryGroup `json:"porProduto"`
+	PorMarca   []ExpiryGroup `json:"porMarca"`
+}
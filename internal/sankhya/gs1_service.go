@@ -0,0 +1,85 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"zenith-go/internal/gs1"
+)
+
+// applyGS1Scan trata o campo opcional "gs1" do payload: valida o GTIN e a validade da etiqueta
+// contra o endereço de origem e preenche a quantidade quando o operador não a informou
+func (c *Client) applyGS1Scan(ctx context.Context, input TransactionInput) error {
+	raw := strings.TrimSpace(safeString(input.Payload["gs1"]))
+	if raw == "" {
+		return nil
+	}
+
+	scan, err := gs1.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrGS1Invalido, err)
+	}
+
+	var codArm, sequencia int
+	if input.Type == "correcao" {
+		codArm = int(safeFloat64(input.Payload["codarm"]))
+		sequencia = int(safeFloat64(input.Payload["sequencia"]))
+	} else if origemMap, ok := input.Payload["origem"].(map[string]any); ok {
+		codArm = int(safeFloat64(origemMap["codarm"]))
+		sequencia = int(safeFloat64(origemMap["sequencia"]))
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT ENDE.CODPROD, 
+		       TO_CHAR(ENDE.DATVAL, 'DD/MM/YYYY') AS DATVAL
+		  FROM AD_CADEND ENDE 
+		 WHERE ENDE.CODARM = %d AND ENDE.SEQEND = %d`, codArm, sequencia)
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar endereço da etiqueta: %w", err)
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("item de origem não encontrado no estoque")
+	}
+	codProd := int(safeFloat64(rows[0][0]))
+	datVal := safeString(rows[0][1])
+
+	if scan.GTIN != "" {
+		var inList []string
+		for _, cand := range scan.GTINCandidates() {
+			inList = append(inList, "'"+sanitizeStringForSql(cand)+"'")
+		}
+		sqlGtin := fmt.Sprintf(`SELECT COUNT(*) FROM TGFVOA WHERE CODPROD = %d AND CODBARRA IN (%s)`, codProd, strings.Join(inList, ", "))
+		rows, err := c.executeQuery(ctx, sqlGtin)
+		if err != nil {
+			return fmt.Errorf("erro ao validar GTIN: %w", err)
+		}
+		if len(rows) == 0 || safeFloat64(rows[0][0]) == 0 {
+			slog.Warn("GTIN da etiqueta diverge do produto do endereço", "gtin", scan.GTIN, "codprod", codProd, "codArm", codArm, "seqEnd", sequencia)
+			return ErrGS1ProdutoDivergente
+		}
+	}
+
+	if scan.HasValidade() && datVal != "" && datVal != scan.ValidadeBR() {
+		slog.Warn("Validade da etiqueta diverge do endereço", "etiqueta", scan.ValidadeBR(), "endereco", datVal, "codArm", codArm, "seqEnd", sequencia)
+		return fmt.Errorf("%w (etiqueta %s, endereço %s)", ErrGS1ValidadeDivergente, scan.ValidadeBR(), datVal)
+	}
+
+	// A quantidade digitada pelo operador sempre prevalece sobre a da etiqueta
+	if scan.Quantidade > 0 {
+		switch input.Type {
+		case "baixa":
+			if safeFloat64(input.Payload["quantidade"]) == 0 {
+				input.Payload["quantidade"] = scan.Quantidade
+			}
+		case "transferencia", "picking":
+			if destMap, ok := input.Payload["destino"].(map[string]any); ok && safeFloat64(destMap["quantidade"]) == 0 {
+				destMap["quantidade"] = scan.Quantidade
+			}
+		}
+	}
+
+	slog.Debug("Etiqueta GS1 aplicada à transação", "type", input.Type, "gtin", scan.GTIN, "lote", scan.Lote, "validade", scan.ValidadeBR(), "qtd", scan.Quantidade)
+	return nil
+}
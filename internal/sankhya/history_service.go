@@ -0,0 +1,401 @@
+package sankhya
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+const (
+	historyDefaultLimit = 50
+	historyMaxLimit     = 500
+)
+
+// historyCursor é a posição (chave de ordenação) do último item entregue
+type historyCursor struct {
+	SortKey string
+	Id      int
+	Tipo    string
+	SeqIte  int
+}
+
+func (hc historyCursor) encode() string {
+	raw := fmt.Sprintf("%s|%d|%s|%d", hc.SortKey, hc.Id, hc.Tipo, hc.SeqIte)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(s string) (*historyCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrHistoryCursorInvalido
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 4 || !onlyDigitsRegex.MatchString(parts[0]) || (parts[2] != "MOV" && parts[2] != "CORRECAO") {
+		return nil, ErrHistoryCursorInvalido
+	}
+	id, err1 := strconv.Atoi(parts[1])
+	seqIte, err2 := strconv.Atoi(parts[3])
+	if err1 != nil || err2 != nil {
+		return nil, ErrHistoryCursorInvalido
+	}
+	return &historyCursor{SortKey: parts[0], Id: id, Tipo: parts[2], SeqIte: seqIte}, nil
+}
+
+// SearchHistory é a versão paginada (por cursor) e filtrável de GetHistory
+func (c *Client) SearchHistory(ctx context.Context, f HistoryFilter) (*HistoryPage, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = historyDefaultLimit
+	}
+	if limit > historyMaxLimit {
+		limit = historyMaxLimit
+	}
+
+	tipo := strings.ToUpper(strings.TrimSpace(f.Tipo))
+	if tipo != "" && tipo != "MOV" && tipo != "CORRECAO" {
+		return nil, fmt.Errorf("%w: tipo '%s'", ErrHistoryFiltroInvalido, f.Tipo)
+	}
+
+	var cursor *historyCursor
+	if f.Cursor != "" {
+		var err error
+		if cursor, err = decodeHistoryCursor(f.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sem armazém liberado não há o que listar
+	if len(f.Armazens) == 0 {
+		return &HistoryPage{Items: []HistoryPageItem{}}, nil
+	}
+
+	safeDtIni := sanitizeStringForSql(f.DtIni)
+	safeDtFim := sanitizeStringForSql(f.DtFim)
+
+	// Filtros aplicados dentro de cada ramo do UNION para aproveitar os índices
+	movWhere := []string{
+		"IBX.APP = 'S'",
+		fmt.Sprintf("TRUNC(BXA.DATGER) BETWEEN TO_DATE('%s', 'DD/MM/YYYY') AND TO_DATE('%s', 'DD/MM/YYYY')", safeDtIni, safeDtFim),
+	}
+	corWhere := []string{
+		fmt.Sprintf("TRUNC(H.DTHOPER) BETWEEN TO_DATE('%s', 'DD/MM/YYYY') AND TO_DATE('%s', 'DD/MM/YYYY')", safeDtIni, safeDtFim),
+	}
+	// Escopo do usuário: origem e destino do movimento precisam estar em armazéns liberados
+	armazens := joinInts(f.Armazens)
+	movWhere = append(movWhere, fmt.Sprintf("IBX.CODARM IN (%s)", armazens), fmt.Sprintf("(IBX.ARMDES IS NULL OR IBX.ARMDES IN (%s))", armazens))
+	corWhere = append(corWhere, fmt.Sprintf("H.CODARM IN (%s)", armazens))
+	if f.CodUsu > 0 {
+		movWhere = append(movWhere, fmt.Sprintf("BXA.USUGER = %d", f.CodUsu))
+		corWhere = append(corWhere, fmt.Sprintf("H.CODUSU = %d", f.CodUsu))
+	}
+	if f.CodArm > 0 {
+		movWhere = append(movWhere, fmt.Sprintf("(IBX.CODARM = %d OR IBX.ARMDES = %d)", f.CodArm, f.CodArm))
+		corWhere = append(corWhere, fmt.Sprintf("H.CODARM = %d", f.CodArm))
+	}
+	if f.SeqEnd > 0 {
+		movWhere = append(movWhere, fmt.Sprintf("(IBX.SEQEND = %d OR IBX.ENDDES = '%d')", f.SeqEnd, f.SeqEnd))
+		corWhere = append(corWhere, fmt.Sprintf("H.SEQEND = %d", f.SeqEnd))
+	}
+	if f.CodProd > 0 {
+		movWhere = append(movWhere, fmt.Sprintf("IBX.CODPROD = %d", f.CodProd))
+		corWhere = append(corWhere, fmt.Sprintf("H.CODPROD = %d", f.CodProd))
+	}
+
+	var branches []string
+	if tipo == "" || tipo == "MOV" {
+		branches = append(branches, fmt.Sprintf(`
+		SELECT 'MOV' AS TIPO, 
+		       BXA.DATGER, 
+		       TO_CHAR(BXA.DATGER, 'HH24:MI:SS') AS HORA, 
+		       IBX.CODARM, 
+		       IBX.SEQEND, 
+		       IBX.ARMDES, 
+		       IBX.ENDDES, 
+		       IBX.CODPROD, 
+		       PRO.DESCRPROD, 
+		       PRO.MARCA, 
+		       (SELECT MAX(V.DESCRDANFE) 
+		        FROM TGFVOA V 
+		        WHERE V.CODPROD = IBX.CODPROD 
+		          AND V.CODVOL = PRO.CODVOL) AS DERIVACAO, 
+		       IBX.QTDPRO,
+		       NULL AS QUANT_ANT, 
+		       NULL AS QTD_ATUAL, 
+		       BXA.SEQBAI AS ID_OPERACAO, 
+		       IBX.SEQITE,
+		       BXA.USUGER AS CODUSU,
+		       TO_CHAR(BXA.DATGER, 'YYYYMMDDHH24MISS') AS SORTKEY
+		FROM AD_BXAEND BXA 
+		JOIN AD_IBXEND IBX ON IBX.SEQBAI = BXA.SEQBAI 
+		LEFT JOIN TGFPRO PRO ON IBX.CODPROD = PRO.CODPROD
+		WHERE %s`, strings.Join(movWhere, "\n		  AND ")))
+	}
+	if tipo == "" || tipo == "CORRECAO" {
+		branches = append(branches, fmt.Sprintf(`
+		SELECT 'CORRECAO' AS TIPO, 
+		       H.DTHOPER, 
+		       TO_CHAR(H.DTHOPER, 'HH24:MI:SS') AS HORA, 
+		       H.CODARM, 
+		       H.SEQEND, 
+		       NULL, 
+		       NULL, 
+		       H.CODPROD, 
+		       (SELECT P.DESCRPROD FROM TGFPRO P WHERE P.CODPROD = H.CODPROD), 
+		       H.MARCA, 
+		       H.DERIV, 
+		       NULL, 
+		       H.QUANT, 
+		       H.QATUAL, 
+		       H.NUMUNICO, 
+		       NULL,
+		       H.CODUSU,
+		       TO_CHAR(H.DTHOPER, 'YYYYMMDDHH24MISS')
+		FROM AD_HISTENDAPP H
+		WHERE %s`, strings.Join(corWhere, "\n		  AND ")))
+	}
+
+	cursorWhere := ""
+	if cursor != nil {
+		cursorWhere = fmt.Sprintf(`
+		WHERE (U.SORTKEY < '%[1]s')
+		   OR (U.SORTKEY = '%[1]s' AND U.ID_OPERACAO < %[2]d)
+		   OR (U.SORTKEY = '%[1]s' AND U.ID_OPERACAO = %[2]d AND U.TIPO > '%[3]s')
+		   OR (U.SORTKEY = '%[1]s' AND U.ID_OPERACAO = %[2]d AND U.TIPO = '%[3]s' AND NVL(U.SEQITE, 0) > %[4]d)`,
+			cursor.SortKey, cursor.Id, cursor.Tipo, cursor.SeqIte)
+	}
+
+	// Busca limit+1 para saber se existe próxima página. O ROWNUM fica junto do ORDER BY
+	// (o join com TSIUSU não preserva a ordem), e a ordem é repetida na consulta externa
+	sql := fmt.Sprintf(`
+		SELECT P.*, USU.NOMEUSUCPLT 
+		  FROM (
+		        SELECT O.* 
+		          FROM (
+		                SELECT U.* 
+		                  FROM (%s) U %s
+		                 ORDER BY U.SORTKEY DESC, U.ID_OPERACAO DESC, U.TIPO ASC, NVL(U.SEQITE, 0) ASC
+		          ) O
+		         WHERE ROWNUM <= %d
+		  ) P
+		  LEFT JOIN TSIUSU USU ON USU.CODUSU = P.CODUSU
+		 ORDER BY P.SORTKEY DESC, P.ID_OPERACAO DESC, P.TIPO ASC, NVL(P.SEQITE, 0) ASC`,
+		strings.Join(branches, "\n\n		UNION ALL\n"), cursorWhere, limit+1)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &HistoryPage{Items: []HistoryPageItem{}}
+	var last historyCursor
+	for i, row := range rows {
+		if i == limit {
+			page.HasMore = true
+			break
+		}
+
+		item := HistoryPageItem{
+			HistoryItem: HistoryItem{
+				Tipo:       safeString(row[0]),
+				DatGer:     safeString(row[1]),
+				Hora:       safeString(row[2]),
+				CodArm:     int(safeFloat64(row[3])),
+				SeqEnd:     int(safeFloat64(row[4])),
+				ArmDes:     safeString(row[5]),
+				EndDes:     safeString(row[6]),
+				CodProd:    int(safeFloat64(row[7])),
+				DescrProd:  safeString(row[8]),
+				Marca:      safeString(row[9]),
+				Derivacao:  safeString(row[10]),
+				QtdProd:    safeFloat64(row[11]),
+				QuantAnt:   safeFloat64(row[12]),
+				QtdAtual:   safeFloat64(row[13]),
+				IdOperacao: int(safeFloat64(row[14])),
+				SeqIte:     int(safeFloat64(row[15])),
+			},
+			CodUsu:  int(safeFloat64(row[16])),
+			NomeUsu: safeString(row[18]),
+		}
+		page.Items = append(page.Items, item)
+		last = historyCursor{SortKey: safeString(row[17]), Id: item.IdOperacao, Tipo: item.Tipo, SeqIte: item.SeqIte}
+	}
+
+	if page.HasMore {
+		page.NextCursor = last.encode()
+	}
+
+	slog.Debug("Página de histórico retornada", "count", len(page.Items), "hasMore", page.HasMore)
+	return page, nil
+}
+
+// GetOperationDetail retorna o cabeçalho, os itens e os saldos antes/depois de uma operação
+func (c *Client) GetOperationDetail(ctx context.Context, tipo string, id int) (*OperationDetail, error) {
+	switch strings.ToUpper(tipo) {
+	case "MOV":
+		return c.getMovimentacaoDetail(ctx, id)
+	case "CORRECAO":
+		return c.getCorrecaoDetail(ctx, id)
+	}
+	return nil, fmt.Errorf("%w: tipo '%s'", ErrHistoryFiltroInvalido, tipo)
+}
+
+func (c *Client) getMovimentacaoDetail(ctx context.Context, seqBai int) (*OperationDetail, error) {
+	sqlHeader := fmt.Sprintf(`
+		SELECT BXA.SEQBAI, 
+		       TO_CHAR(BXA.DATGER, 'DD/MM/YYYY') AS DATA, 
+		       TO_CHAR(BXA.DATGER, 'HH24:MI:SS') AS HORA, 
+		       BXA.USUGER, 
+		       USU.NOMEUSUCPLT
+		  FROM AD_BXAEND BXA
+		  LEFT JOIN TSIUSU USU ON USU.CODUSU = BXA.USUGER
+		 WHERE BXA.SEQBAI = %d`, seqBai)
+
+	rows, err := c.executeQuery(ctx, sqlHeader)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrOperacaoNaoEncontrada
+	}
+
+	detail := &OperationDetail{
+		Tipo:       "MOV",
+		IdOperacao: int(safeFloat64(rows[0][0])),
+		Data:       safeString(rows[0][1]),
+		Hora:       safeString(rows[0][2]),
+		CodUsu:     int(safeFloat64(rows[0][3])),
+		NomeUsu:    safeString(rows[0][4]),
+		Itens:      []OperationItem{},
+	}
+
+	// Os saldos atuais só representam o "depois" se nenhum movimento ou correção
+	// posterior tocou o endereço (AD_BXAEND guarda apenas a data, por isso TRUNC)
+	sqlItems := fmt.Sprintf(`
+		SELECT IBX.SEQITE, 
+		       IBX.CODARM, 
+		       IBX.SEQEND, 
+		       IBX.ARMDES, 
+		       IBX.ENDDES, 
+		       IBX.CODPROD, 
+		       PRO.DESCRPROD, 
+		       PRO.MARCA, 
+		       IBX.QTDPRO, 
+		       ORI.QTDPRO AS SALDO_ORIGEM, 
+		       DST.QTDPRO AS SALDO_DESTINO,
+		       (SELECT COUNT(*) 
+		          FROM AD_IBXEND L 
+		         WHERE L.SEQBAI > IBX.SEQBAI 
+		           AND ((L.CODARM = IBX.CODARM AND L.SEQEND = IBX.SEQEND) 
+		             OR (L.ARMDES = IBX.CODARM AND L.ENDDES = TO_CHAR(IBX.SEQEND))
+		             OR (IBX.ARMDES IS NOT NULL AND L.CODARM = IBX.ARMDES AND TO_CHAR(L.SEQEND) = IBX.ENDDES)
+		             OR (IBX.ARMDES IS NOT NULL AND L.ARMDES = IBX.ARMDES AND L.ENDDES = IBX.ENDDES))) 
+		       + (SELECT COUNT(*) 
+		            FROM AD_HISTENDAPP H, AD_BXAEND B 
+		           WHERE B.SEQBAI = IBX.SEQBAI 
+		             AND TRUNC(H.DTHOPER) >= TRUNC(B.DATGER)
+		             AND ((H.CODARM = IBX.CODARM AND H.SEQEND = IBX.SEQEND) 
+		               OR (H.CODARM = IBX.ARMDES AND TO_CHAR(H.SEQEND) = IBX.ENDDES))) AS MOV_POSTERIORES
+		  FROM AD_IBXEND IBX
+		  LEFT JOIN TGFPRO PRO ON PRO.CODPROD = IBX.CODPROD
+		  LEFT JOIN AD_CADEND ORI ON ORI.CODARM = IBX.CODARM AND ORI.SEQEND = IBX.SEQEND
+		  LEFT JOIN AD_CADEND DST ON DST.CODARM = IBX.ARMDES AND TO_CHAR(DST.SEQEND) = IBX.ENDDES
+		 WHERE IBX.SEQBAI = %d
+		 ORDER BY IBX.SEQITE`, seqBai)
+
+	rows, err = c.executeQuery(ctx, sqlItems)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		qtd := safeFloat64(row[8])
+		item := OperationItem{
+			SeqIte:         int(safeFloat64(row[0])),
+			CodArm:         int(safeFloat64(row[1])),
+			SeqEnd:         int(safeFloat64(row[2])),
+			ArmDes:         int(safeFloat64(row[3])),
+			EndDes:         safeString(row[4]),
+			CodProd:        int(safeFloat64(row[5])),
+			DescrProd:      safeString(row[6]),
+			Marca:          safeString(row[7]),
+			Quantidade:     qtd,
+			SaldoConfiavel: safeFloat64(row[11]) == 0,
+		}
+
+		if item.SaldoConfiavel {
+			origemDepois := safeFloat64(row[9])
+			origemAntes := origemDepois + qtd
+			item.OrigemDepois = &origemDepois
+			item.OrigemAntes = &origemAntes
+
+			if item.ArmDes > 0 && item.EndDes != "" {
+				destinoDepois := safeFloat64(row[10])
+				destinoAntes := destinoDepois - qtd
+				item.DestinoDepois = &destinoDepois
+				item.DestinoAntes = &destinoAntes
+			}
+		}
+
+		detail.Itens = append(detail.Itens, item)
+	}
+
+	return detail, nil
+}
+
+func (c *Client) getCorrecaoDetail(ctx context.Context, numUnico int) (*OperationDetail, error) {
+	sql := fmt.Sprintf(`
+		SELECT H.NUMUNICO, 
+		       TO_CHAR(H.DTHOPER, 'DD/MM/YYYY') AS DATA, 
+		       TO_CHAR(H.DTHOPER, 'HH24:MI:SS') AS HORA, 
+		       H.CODUSU, 
+		       USU.NOMEUSUCPLT, 
+		       H.CODUSUAPR, 
+		       H.CODARM, 
+		       H.SEQEND, 
+		       H.CODPROD, 
+		       PRO.DESCRPROD, 
+		       H.MARCA, 
+		       H.QUANT, 
+		       H.QATUAL
+		  FROM AD_HISTENDAPP H
+		  LEFT JOIN TGFPRO PRO ON PRO.CODPROD = H.CODPROD
+		  LEFT JOIN TSIUSU USU ON USU.CODUSU = H.CODUSU
+		 WHERE H.NUMUNICO = %d`, numUnico)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrOperacaoNaoEncontrada
+	}
+	row := rows[0]
+
+	// A correção grava o saldo anterior (QUANT) e o novo (QATUAL), então é sempre confiável
+	antes := safeFloat64(row[11])
+	depois := safeFloat64(row[12])
+
+	return &OperationDetail{
+		Tipo:        "CORRECAO",
+		IdOperacao:  int(safeFloat64(row[0])),
+		Data:        safeString(row[1]),
+		Hora:        safeString(row[2]),
+		CodUsu:      int(safeFloat64(row[3])),
+		NomeUsu:     safeString(row[4]),
+		CodUsuAprov: int(safeFloat64(row[5])),
+		Itens: []OperationItem{{
+			CodArm:         int(safeFloat64(row[6])),
+			SeqEnd:         int(safeFloat64(row[7])),
+			CodProd:        int(safeFloat64(row[8])),
+			DescrProd:      safeString(row[9]),
+			Marca:          safeString(row[10]),
+			Quantidade:     depois - antes,
+			SaldoConfiavel: true,
+			OrigemAntes:    &antes,
+			OrigemDepois:   &depois,
+		}},
+	}, nil
+}
@@ -0,0 +1,69 @@
+package sankhya
+
+// HistoryFilter reúne os filtros da consulta paginada de histórico
+type HistoryFilter struct {
+	DtIni   string `json:"dtIni"`
+	DtFim   string `json:"dtFim"`
+	CodUsu  int    `json:"codUsu"`
+	Tipo    string `json:"tipo"` // MOV ou CORRECAO (vazio = ambos)
+	CodArm  int    `json:"codArm"`
+	SeqEnd  int    `json:"seqEnd"`
+	CodProd int    `json:"codProd"`
+	Cursor  string `json:"cursor"`
+	Limit   int    `json:"limit"`
+
+	// Armazens é o escopo do usuário (AD_PERMEND), preenchido pelo handler. Vazio = nada
+	Armazens []int `json:"-"`
+}
+
+// HistoryPageItem é uma linha do histórico com o usuário que executou
+type HistoryPageItem struct {
+	HistoryItem
+	CodUsu  int    `json:"codUsu"`
+	NomeUsu string `json:"nomeUsu"`
+}
+
+// HistoryPage é uma página do histórico; NextCursor vazio indica o fim
+type HistoryPage struct {
+	Items      []HistoryPageItem `json:"items"`
+	NextCursor string            `json:"nextCursor"`
+	HasMore    bool              `json:"hasMore"`
+}
+
+// OperationDetailInput identifica a operação (SEQBAI para MOV, NUMUNICO para CORRECAO)
+type OperationDetailInput struct {
+	Tipo       string `json:"tipo"`
+	IdOperacao int    `json:"idOperacao"`
+}
+
+// OperationItem é um item da operação com o saldo do endereço antes e depois
+type OperationItem struct {
+	SeqIte     int     `json:"seqIte"`
+	CodArm     int     `json:"codArm"`
+	SeqEnd     int     `json:"seqEnd"`
+	ArmDes     int     `json:"armDes,omitempty"`
+	EndDes     string  `json:"endDes,omitempty"`
+	CodProd    int     `json:"codProd"`
+	DescrProd  string  `json:"descrProd"`
+	Marca      string  `json:"marca"`
+	Quantidade float64 `json:"quantidade"`
+
+	// Saldos só são devolvidos quando confiáveis (nenhum movimento posterior no endereço)
+	SaldoConfiavel bool     `json:"saldoConfiavel"`
+	OrigemAntes    *float64 `json:"origemAntes,omitempty"`
+	OrigemDepois   *float64 `json:"origemDepois,omitempty"`
+	DestinoAntes   *float64 `json:"destinoAntes,omitempty"`
+	DestinoDepois  *float64 `json:"destinoDepois,omitempty"`
+}
+
+// OperationDetail é o cabeçalho da operação com todos os seus itens
+type OperationDetail struct {
+	Tipo        string          `json:"tipo"`
+	IdOperacao  int             `json:"idOperacao"`
+	Data        string          `json:"data"`
+	Hora        string          `json:"hora"`
+	CodUsu      int             `json:"codUsu"`
+	NomeUsu     string          `json:"nomeUsu"`
+	CodUsuAprov int             `json:"codUsuAprov,omitempty"`
+	Itens       []OperationItem `json:"itens"`
+}
@@ -0,0 +1,81 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GetLabelAddresses carrega os dados de etiqueta dos endereços, na ordem pedida.
+// Se algum endereço não existir no armazém, retorna ErrItemNotFound com a lista dos faltantes.
+func (c *Client) GetLabelAddresses(ctx context.Context, codArm int, sequencias []int) ([]LabelAddressData, error) {
+	if len(sequencias) == 0 {
+		return []LabelAddressData{}, nil
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT ENDE.CODARM, 
+		       ARM.DESARM, 
+		       ENDE.SEQEND, 
+		       ENDE.CODRUA, 
+		       ENDE.CODPRD, 
+		       ENDE.CODAPT, 
+		       ENDE.ENDPIC, 
+		       ENDE.CODPROD, 
+		       PRO.DESCRPROD, 
+		       PRO.MARCA, 
+		       (SELECT MAX(VOA.DESCRDANFE) 
+		          FROM TGFVOA VOA 
+		         WHERE VOA.CODPROD = ENDE.CODPROD 
+		           AND VOA.CODVOL = ENDE.CODVOL) AS DERIVACAO, 
+		       TO_CHAR(ENDE.DATVAL, 'DD/MM/YYYY') AS DATVAL, 
+		       NVL(ENDE.QTDPRO, 0) AS QTDPRO, 
+		       ENDE.CODVOL
+		  FROM AD_CADEND ENDE 
+		  LEFT JOIN AD_CADARM ARM ON ARM.CODARM = ENDE.CODARM 
+		  LEFT JOIN TGFPRO PRO ON PRO.CODPROD = ENDE.CODPROD
+		 WHERE ENDE.CODARM = %d 
+		   AND ENDE.SEQEND IN (%s)`, codArm, joinInts(sequencias))
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	bySeq := make(map[int]LabelAddressData, len(rows))
+	for _, row := range rows {
+		d := LabelAddressData{
+			CodArm:    int(safeFloat64(row[0])),
+			DesArm:    safeString(row[1]),
+			SeqEnd:    int(safeFloat64(row[2])),
+			CodRua:    safeString(row[3]),
+			CodPrd:    int(safeFloat64(row[4])),
+			CodApt:    safeString(row[5]),
+			EndPic:    safeString(row[6]),
+			CodProd:   int(safeFloat64(row[7])),
+			DescrProd: safeString(row[8]),
+			Marca:     safeString(row[9]),
+			Derivacao: safeString(row[10]),
+			DatVal:    safeString(row[11]),
+			QtdPro:    safeFloat64(row[12]),
+			CodVol:    safeString(row[13]),
+		}
+		bySeq[d.SeqEnd] = d
+	}
+
+	result := make([]LabelAddressData, 0, len(sequencias))
+	var faltantes []string
+	for _, seq := range sequencias {
+		d, ok := bySeq[seq]
+		if !ok {
+			faltantes = append(faltantes, fmt.Sprint(seq))
+			continue
+		}
+		result = append(result, d)
+	}
+	if len(faltantes) > 0 {
+		return nil, fmt.Errorf("%w: endereço(s) %s no armazém %d", ErrItemNotFound, strings.Join(faltantes, ", "), codArm)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,28 @@
+package sankhya
+
+// LabelInput é o pedido de etiqueta (endereço ou palete) para um ou mais endereços do armazém
+type LabelInput struct {
+	Tipo       string   `json:"tipo"` // endereco | palete
+	CodArm     int      `json:"codArm"`
+	Sequencias []int    `json:"sequencias"`
+	Quantidade *float64 `json:"quantidade"` // Palete: sobrescreve o saldo do endereço
+	Copias     int      `json:"copias"`
+}
+
+// LabelAddressData reúne o cadastro do endereço e o produto armazenado, para montar etiquetas
+type LabelAddressData struct {
+	CodArm    int
+	DesArm    string
+	SeqEnd    int
+	CodRua    string
+	CodPrd    int
+	CodApt    string
+	EndPic    string
+	CodProd   int
+	DescrProd string
+	Marca     string
+	Derivacao string
+	DatVal    string // DD/MM/YYYY
+	QtdPro    float64
+	CodVol    string
+}
@@ -0,0 +1,156 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+)
+
+// GetOccupancyMap monta o mapa do armazém por rua, prédio e apartamento, com a ocupação
+// por rua e por nível. Ocupado = endereço com produto e saldo positivo.
+func (c *Client) GetOccupancyMap(ctx context.Context, codArm int, codRua string) (*OccupancyMap, error) {
+	filtroRua := ""
+	if rua := strings.TrimSpace(codRua); rua != "" {
+		filtroRua = fmt.Sprintf("AND ENDE.CODRUA = '%s'", sanitizeStringForSql(rua))
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT ENDE.SEQEND, 
+		       ENDE.CODRUA, 
+		       ENDE.CODPRD, 
+		       ENDE.CODAPT, 
+		       ENDE.CODPROD, 
+		       PRO.DESCRPROD, 
+		       PRO.MARCA, 
+		       NVL(ENDE.QTDPRO, 0) AS QTDPRO, 
+		       ENDE.CODVOL, 
+		       ENDE.ENDPIC, 
+		       TO_CHAR(ENDE.DATVAL, 'DD/MM/YYYY') AS DATVAL
+		  FROM AD_CADEND ENDE 
+		  LEFT JOIN TGFPRO PRO ON PRO.CODPROD = ENDE.CODPROD
+		 WHERE ENDE.CODARM = %d 
+		   %s
+		 ORDER BY ENDE.CODRUA, ENDE.CODPRD, ENDE.CODAPT, ENDE.SEQEND`, codArm, filtroRua)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	mapa := &OccupancyMap{CodArm: codArm, Niveis: []OccupancyLevel{}, Ruas: []OccupancyStreet{}}
+	niveisArmazem := newOccupancyLevels()
+
+	var rua *OccupancyStreet
+	var niveisRua *occupancyLevels
+	var predio *OccupancyBuilding
+
+	// As linhas vêm ordenadas, então cada rua/prédio é fechado quando o próximo começa
+	closeBuilding := func() {
+		if predio != nil {
+			predio.OccupancyStats.finish()
+			rua.Predios = append(rua.Predios, *predio)
+			predio = nil
+		}
+	}
+	closeStreet := func() {
+		closeBuilding()
+		if rua != nil {
+			rua.OccupancyStats.finish()
+			rua.Niveis = niveisRua.list()
+			mapa.Ruas = append(mapa.Ruas, *rua)
+			rua = nil
+		}
+	}
+
+	for _, row := range rows {
+		codRuaRow := safeString(row[1])
+		codPrd := int(safeFloat64(row[2]))
+
+		end := OccupancyAddress{
+			SeqEnd: int(safeFloat64(row[0])),
+			CodApt: safeString(row[3]),
+			QtdPro: safeFloat64(row[7]),
+			EndPic: safeString(row[9]),
+		}
+		if codProd := int(safeFloat64(row[4])); codProd > 0 {
+			end.CodProd = codProd
+			end.DescrProd = safeString(row[5])
+			end.Marca = safeString(row[6])
+			end.CodVol = safeString(row[8])
+			end.DatVal = safeString(row[10])
+			end.Ocupado = end.QtdPro > 0
+		}
+
+		if rua == nil || rua.CodRua != codRuaRow {
+			closeStreet()
+			rua = &OccupancyStreet{CodRua: codRuaRow, Niveis: []OccupancyLevel{}, Predios: []OccupancyBuilding{}}
+			niveisRua = newOccupancyLevels()
+		}
+		if predio == nil || predio.CodPrd != codPrd {
+			closeBuilding()
+			predio = &OccupancyBuilding{CodPrd: codPrd, Apartamentos: []OccupancyAddress{}}
+		}
+
+		predio.Apartamentos = append(predio.Apartamentos, end)
+		predio.OccupancyStats.add(end.Ocupado)
+		rua.OccupancyStats.add(end.Ocupado)
+		mapa.OccupancyStats.add(end.Ocupado)
+		niveisRua.add(end.CodApt, end.Ocupado)
+		niveisArmazem.add(end.CodApt, end.Ocupado)
+	}
+	closeStreet()
+
+	mapa.OccupancyStats.finish()
+	mapa.Niveis = niveisArmazem.list()
+
+	slog.Debug("Mapa de ocupação gerado", "codArm", codArm, "ruas", len(mapa.Ruas), "enderecos", mapa.Total)
+	return mapa, nil
+}
+
+func (s *OccupancyStats) add(ocupado bool) {
+	s.Total++
+	if ocupado {
+		s.Ocupados++
+	}
+}
+
+// finish calcula o percentual com uma casa decimal
+func (s *OccupancyStats) finish() {
+	if s.Total > 0 {
+		s.Percentual = math.Round(float64(s.Ocupados)*1000/float64(s.Total)) / 10
+	}
+}
+
+// occupancyLevels acumula a ocupação por CODAPT
+type occupancyLevels struct {
+	byApt map[string]*OccupancyLevel
+	order []string
+}
+
+func newOccupancyLevels() *occupancyLevels {
+	return &occupancyLevels{byApt: make(map[string]*OccupancyLevel)}
+}
+
+func (l *occupancyLevels) add(codApt string, ocupado bool) {
+	nivel, ok := l.byApt[codApt]
+	if !ok {
+		nivel = &OccupancyLevel{CodApt: codApt}
+		l.byApt[codApt] = nivel
+		l.order = append(l.order, codApt)
+	}
+	nivel.OccupancyStats.add(ocupado)
+}
+
+func (l *occupancyLevels) list() []OccupancyLevel {
+	sort.Strings(l.order)
+	list := make([]OccupancyLevel, 0, len(l.order))
+	for _, codApt := range l.order {
+		nivel := l.byApt[codApt]
+		nivel.OccupancyStats.finish()
+		list = append(list, *nivel)
+	}
+	return list
+}
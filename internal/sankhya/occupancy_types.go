@@ -0,0 +1,57 @@
+package sankhya
+
+// OccupancyInput filtra o mapa de ocupação (CodRua opcional)
+type OccupancyInput struct {
+	CodArm int    `json:"codArm"`
+	CodRua string `json:"codRua"`
+}
+
+// OccupancyStats é a contagem de endereços ocupados (com produto e saldo) sobre o total
+type OccupancyStats struct {
+	Total      int     `json:"total"`
+	Ocupados   int     `json:"ocupados"`
+	Percentual float64 `json:"percentual"`
+}
+
+// OccupancyAddress é uma posição do rack (CODAPT dentro do prédio)
+type OccupancyAddress struct {
+	CodApt    string  `json:"codApt"`
+	SeqEnd    int     `json:"seqEnd"`
+	Ocupado   bool    `json:"ocupado"`
+	CodProd   int     `json:"codProd,omitempty"`
+	DescrProd string  `json:"descrProd,omitempty"`
+	Marca     string  `json:"marca,omitempty"`
+	QtdPro    float64 `json:"qtdPro"`
+	CodVol    string  `json:"codVol,omitempty"`
+	EndPic    string  `json:"endPic"`
+	DatVal    string  `json:"datVal,omitempty"`
+}
+
+// OccupancyBuilding é um prédio (CODPRD) da rua com seus apartamentos
+type OccupancyBuilding struct {
+	CodPrd int `json:"codPrd"`
+	OccupancyStats
+	Apartamentos []OccupancyAddress `json:"apartamentos"`
+}
+
+// OccupancyLevel é a ocupação de um nível (CODAPT) somando todos os prédios
+type OccupancyLevel struct {
+	CodApt string `json:"codApt"`
+	OccupancyStats
+}
+
+// OccupancyStreet é uma rua (CODRUA) com prédios e ocupação por nível
+type OccupancyStreet struct {
+	CodRua string `json:"codRua"`
+	OccupancyStats
+	Niveis  []OccupancyLevel    `json:"niveis"`
+	Predios []OccupancyBuilding `json:"predios"`
+}
+
+// OccupancyMap é o armazém agrupado CODRUA → CODPRD → CODAPT
+type OccupancyMap struct {
+	CodArm int `json:"codArm"`
+	OccupancyStats
+	Niveis []OccupancyLevel  `json:"niveis"`
+	Ruas   []OccupancyStreet `json:"ruas"`
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 )
 
 func (c *Client) GetUserPermissions(ctx context.Context, codUsu int) (*UserPermissions, error) {
@@ -12,12 +14,12 @@ func (c *Client) GetUserPermissions(ctx context.Context, codUsu int) (*UserPermi
 		SELECT 
 			LISTAGG(d.CODARM, ', ') WITHIN GROUP (ORDER BY d.CODARM) AS LISTA_CODIGOS, 
 			LISTAGG(d.CODARM || ' - ' || a.DESARM, ', ') WITHIN GROUP (ORDER BY d.CODARM) AS LISTA_NOMES, 
-			p.CODUSU, p.TRANSF, p.BAIXA, p.PICK, p.CORRE, p.BXAPICK, p.CRIAPICK 
+			p.CODUSU, p.TRANSF, p.BAIXA, p.PICK, p.CORRE, p.BXAPICK, p.CRIAPICK, p.APRCORRE, p.HISTGER, p.SUPCONF 
 		FROM AD_APPPERM p 
 		JOIN AD_PERMEND d ON d.NUMREG = p.NUMREG 
 		JOIN AD_CADARM a ON a.CODARM = d.CODARM 
 		WHERE p.CODUSU = %d 
-		GROUP BY p.CODUSU, p.TRANSF, p.BAIXA, p.PICK, p.CORRE, p.BXAPICK, p.CRIAPICK`, codUsu)
+		GROUP BY p.CODUSU, p.TRANSF, p.BAIXA, p.PICK, p.CORRE, p.BXAPICK, p.CRIAPICK, p.APRCORRE, p.HISTGER, p.SUPCONF`, codUsu)
 
 	rows, err := c.executeQuery(ctx, sqlQuery)
 	if err != nil {
@@ -75,5 +77,64 @@ func (c *Client) GetUserPermissions(ctx context.Context, codUsu int) (*UserPermi
 		Corre:        safeBool(row[6]),
 		BxaPick:      safeBool(row[7]),
 		CriaPick:     safeBool(row[8]),
+		AprCorre:     safeBool(row[9]),
+		HistGer:      safeBool(row[10]),
+		SupConf:      safeBool(row[11]),
 	}, nil
-}
\ No newline at end of file
+}
+
+// Armazens converte LISTA_CODIGOS ("1, 2, 5") na lista de armazéns liberados em AD_PERMEND
+func (p *UserPermissions) Armazens() []int {
+	var armazens []int
+	for _, part := range strings.Split(p.ListaCodigos, ",") {
+		if codArm, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			armazens = append(armazens, codArm)
+		}
+	}
+	return armazens
+}
+
+// CanAccessWarehouse indica se o armazém está no escopo do usuário
+func (p *UserPermissions) CanAccessWarehouse(codArm int) bool {
+	for _, allowed := range p.Armazens() {
+		if allowed == codArm {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWarehouses retorna ErrWarehouseNotAllowed para o primeiro armazém fora do escopo
+func (p *UserPermissions) checkWarehouses(codArms ...int) error {
+	for _, codArm := range codArms {
+		if !p.CanAccessWarehouse(codArm) {
+			slog.Warn("Acesso a armazém fora do escopo", "codusu", p.CodUsu, "codArm", codArm, "permitidos", p.ListaCodigos)
+			return fmt.Errorf("%w: armazém %d", ErrWarehouseNotAllowed, codArm)
+		}
+	}
+	return nil
+}
+
+// AuthorizeWarehouses carrega as permissões do usuário e valida todos os armazéns informados
+func (c *Client) AuthorizeWarehouses(ctx context.Context, codUsu int, codArms ...int) (*UserPermissions, error) {
+	perms, err := c.GetUserPermissions(ctx, codUsu)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao verificar permissões: %w", err)
+	}
+	if err := perms.checkWarehouses(codArms...); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
+// HistoryUserFilter define de quem é o histórico consultado. Sem HISTGER o usuário só vê
+// os próprios registros, independente do que o app enviar; com HISTGER, 0 significa todos.
+func (p *UserPermissions) HistoryUserFilter(requested int) int {
+	if p.HistGer {
+		return requested
+	}
+	if requested != p.CodUsu {
+		slog.Warn("Consulta de histórico de terceiros bloqueada", "codusu", p.CodUsu, "solicitado", requested)
+	}
+	return p.CodUsu
+}
@@ -23,8 +23,47 @@ func (c *Client) GetItemDetails(ctx context.Context, codArm int, sequencia strin
 		return nil, ErrItemNotFound
 	}
 
-	row := rows[0]
+	return mapItemDetailRow(rows[0]), nil
+}
+
+// GetItemDetailsBulk busca vários endereços de uma vez (uma query por chamada, agrupando por armazém).
+// Endereços inexistentes simplesmente não aparecem no mapa de retorno.
+func (c *Client) GetItemDetailsBulk(ctx context.Context, enderecos []ItemAddress) (map[ItemAddress]*ItemDetail, error) {
+	result := make(map[ItemAddress]*ItemDetail, len(enderecos))
+	if len(enderecos) == 0 {
+		return result, nil
+	}
+
+	porArmazem := make(map[int][]int)
+	var armazens []int
+	for _, e := range enderecos {
+		if _, ok := porArmazem[e.CodArm]; !ok {
+			armazens = append(armazens, e.CodArm)
+		}
+		porArmazem[e.CodArm] = append(porArmazem[e.CodArm], e.SeqEnd)
+	}
+
+	filtros := make([]string, 0, len(armazens))
+	for _, codArm := range armazens {
+		filtros = append(filtros, fmt.Sprintf("(CODARM = %d AND SEQEND IN (%s))", codArm, joinInts(porArmazem[codArm])))
+	}
+
+	sql := fmt.Sprintf(`SELECT * FROM V_WMS_ITEM_DETALHES WHERE %s`, strings.Join(filtros, " OR "))
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		item := mapItemDetailRow(row)
+		result[ItemAddress{CodArm: item.CodArm, SeqEnd: item.SeqEnd}] = item
+	}
+	return result, nil
+}
 
+// mapItemDetailRow converte uma linha de V_WMS_ITEM_DETALHES (SELECT *, na ordem da view)
+func mapItemDetailRow(row []any) *ItemDetail {
 	// Helpers
 	getInt := func(i int) int {
 		if i >= len(row) || row[i] == nil { return 0 }
@@ -41,7 +80,7 @@ func (c *Client) GetItemDetails(ctx context.Context, codArm int, sequencia strin
 		return fmt.Sprintf("%v", row[i])
 	}
 
-	item := &ItemDetail{
+	return &ItemDetail{
 		CodArm:      getInt(0),
 		SeqEnd:      getInt(1),
 		CodRua:      getString(2),
@@ -57,8 +96,6 @@ func (c *Client) GetItemDetails(ctx context.Context, codArm int, sequencia strin
 		QtdCompleta: getString(12),
 		Derivacao:   getString(13),
 	}
-
-	return item, nil
 }
 
 // GetPickingLocations busca locais de picking alternativos
@@ -103,11 +140,8 @@ func (c *Client) GetPickingLocations(ctx context.Context, codArm int, codProd in
 	return results, nil
 }
 
-// SearchItems busca itens no armazém
-func (c *Client) SearchItems(ctx context.Context, codArm int, filtro string) ([]SearchItemResult, error) {
-	var sqlBuilder strings.Builder
-	
-	sqlBuilder.WriteString(fmt.Sprintf(`
+// searchItemsBaseSQL é o SELECT comum às buscas de endereços (colunas na ordem de mapSearchItemRows)
+const searchItemsBaseSQL = `
 		SELECT /*+ ALL_ROWS */
 			ENDE.SEQEND, 
 			ENDE.CODRUA, 
@@ -127,10 +161,120 @@ func (c *Client) SearchItems(ctx context.Context, codArm int, filtro string) ([]
 			SELECT CODPROD, CODVOL, MAX(DESCRDANFE) AS DERIVACAO 
 			FROM TGFVOA 
 			GROUP BY CODPROD, CODVOL
-		) VOA ON VOA.CODPROD = ENDE.CODPROD AND VOA.CODVOL = ENDE.CODVOL
-		WHERE ENDE.CODARM = %d`, codArm))
+		) VOA ON VOA.CODPROD = ENDE.CODPROD AND VOA.CODVOL = ENDE.CODVOL`
+
+// SearchItems busca itens no armazém
+func (c *Client) SearchItems(ctx context.Context, codArm int, filtro string) ([]SearchItemResult, error) {
+	var sqlBuilder strings.Builder
+	
+	where, seqExata := searchItemsWhere(codArm, filtro)
+	sqlBuilder.WriteString(searchItemsBaseSQL)
+	sqlBuilder.WriteString(where)
+	sqlBuilder.WriteString(" ORDER BY " + searchItemsRelevanceOrder("ENDE.", seqExata))
+	
+	rows, err := c.executeQuery(ctx, sqlBuilder.String())
+	if err != nil {
+		return nil, err
+	}
 
-	orderBy := " ORDER BY ENDE.ENDPIC DESC, ENDE.DATVAL ASC"
+	return mapSearchItemRows(rows), nil
+}
+
+// SearchItemsPaged é a versão paginada de SearchItems, com total e ordenação selecionável
+func (c *Client) SearchItemsPaged(ctx context.Context, q SearchItemsQuery) (*SearchItemsPage, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = searchItemsDefaultLimit
+	}
+	if limit > searchItemsMaxLimit {
+		limit = searchItemsMaxLimit
+	}
+	offset := q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	where, seqExata := searchItemsWhere(q.CodArm, q.Filtro)
+
+	orderBy := searchItemsRelevanceOrder("B.", seqExata)
+	if q.Sort != "" {
+		col, ok := searchItemsSortColumns[strings.ToLower(q.Sort)]
+		if !ok {
+			return nil, fmt.Errorf("%w: '%s'", ErrSearchSortInvalido, q.Sort)
+		}
+		dir := "ASC"
+		switch strings.ToLower(q.Dir) {
+		case "", "asc":
+		case "desc":
+			dir = "DESC"
+		default:
+			return nil, fmt.Errorf("%w: direção '%s'", ErrSearchSortInvalido, q.Dir)
+		}
+		orderBy = fmt.Sprintf("B.%s %s NULLS LAST", col, dir)
+	}
+
+	// SEQEND desempata para a paginação ser estável entre as páginas
+	sql := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT B.*, 
+			       ROW_NUMBER() OVER (ORDER BY %s, B.SEQEND ASC) AS RN, 
+			       COUNT(*) OVER () AS TOTAL
+			  FROM (%s%s) B
+		)
+		WHERE RN BETWEEN %d AND %d
+		ORDER BY RN`, orderBy, searchItemsBaseSQL, where, offset+1, offset+limit)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &SearchItemsPage{
+		Items:  mapSearchItemRows(rows),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if page.Items == nil {
+		page.Items = []SearchItemResult{}
+	}
+	// TOTAL é a última coluna (depois de RN). Página vazia além do fim não traz o total: conta à parte
+	if len(rows) > 0 {
+		page.Total = int(safeFloat64(rows[0][len(rows[0])-1]))
+	} else if offset > 0 {
+		countRows, err := c.executeQuery(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM (%s%s)`, searchItemsBaseSQL, where))
+		if err != nil {
+			return nil, err
+		}
+		if len(countRows) > 0 {
+			page.Total = int(safeFloat64(countRows[0][0]))
+		}
+	}
+	page.HasMore = offset+len(page.Items) < page.Total
+
+	slog.Debug("Página de itens retornada", "codArm", q.CodArm, "count", len(page.Items), "total", page.Total)
+	return page, nil
+}
+
+const (
+	searchItemsDefaultLimit = 100
+	searchItemsMaxLimit     = 500
+)
+
+// searchItemsSortColumns mapeia as ordenações aceitas para as colunas de searchItemsBaseSQL
+var searchItemsSortColumns = map[string]string{
+	"endereco":   "SEQEND",
+	"validade":   "DATVAL",
+	"quantidade": "QTDPRO",
+	"descricao":  "DESCRPROD",
+}
+
+// searchItemsWhere monta o WHERE do filtro; seqExata vem preenchida quando o filtro é numérico
+func searchItemsWhere(codArm int, filtro string) (string, string) {
+	var sqlBuilder strings.Builder
+	seqExata := ""
+
+	sqlBuilder.WriteString(fmt.Sprintf(`
+		WHERE ENDE.CODARM = %d`, codArm))
 
 	if filtro != "" {
 		filtroLimpo := strings.TrimSpace(filtro)
@@ -148,7 +292,7 @@ func (c *Client) SearchItems(ctx context.Context, codArm int, filtro string) ([]
 					)
 				)`, filtroSafe, filtroSafe, filtroSafe, codArm))
 			
-			orderBy = fmt.Sprintf(` ORDER BY CASE WHEN ENDE.SEQEND = %s THEN 0 ELSE 1 END, ENDE.ENDPIC DESC, ENDE.DATVAL ASC`, filtroSafe)
+			seqExata = filtroSafe
 		} else {
 			palavrasChave := strings.Fields(filtroLimpo)
 			if len(palavrasChave) > 0 {
@@ -167,13 +311,19 @@ func (c *Client) SearchItems(ctx context.Context, codArm int, filtro string) ([]
 		}
 	}
 
-	sqlBuilder.WriteString(orderBy)
-	
-	rows, err := c.executeQuery(ctx, sqlBuilder.String())
-	if err != nil {
-		return nil, err
+	return sqlBuilder.String(), seqExata
+}
+
+// searchItemsRelevanceOrder é a ordem padrão: endereço exato primeiro, picking, validade
+func searchItemsRelevanceOrder(prefix string, seqExata string) string {
+	if seqExata != "" {
+		return fmt.Sprintf(`CASE WHEN %[1]sSEQEND = %[2]s THEN 0 ELSE 1 END, %[1]sENDPIC DESC, %[1]sDATVAL ASC`, prefix, seqExata)
 	}
+	return fmt.Sprintf(`%[1]sENDPIC DESC, %[1]sDATVAL ASC`, prefix)
+}
 
+// mapSearchItemRows converte as linhas de searchItemsBaseSQL
+func mapSearchItemRows(rows [][]any) []SearchItemResult {
 	var results []SearchItemResult
 	for _, row := range rows {
 		getInt := func(i int) int {
@@ -207,11 +357,17 @@ func (c *Client) SearchItems(ctx context.Context, codArm int, filtro string) ([]
 		})
 	}
 
-	return results, nil
+	return results
 }
 
-// GetHistory busca o histórico de movimentação
-func (c *Client) GetHistory(ctx context.Context, dtIni string, dtFim string, codUsu int) ([]HistoryItem, error) {
+// GetHistory busca o histórico de movimentação, restrito aos armazéns do usuário (AD_PERMEND)
+func (c *Client) GetHistory(ctx context.Context, dtIni string, dtFim string, codUsu int, armazens []int) ([]HistoryItem, error) {
+	// Sem armazém liberado não há o que listar
+	if len(armazens) == 0 {
+		return nil, nil
+	}
+	armList := joinInts(armazens)
+
 	safeDtIni := sanitizeStringForSql(dtIni)
 	safeDtFim := sanitizeStringForSql(dtFim)
 	
@@ -245,6 +401,8 @@ func (c *Client) GetHistory(ctx context.Context, dtIni string, dtFim string, cod
 		LEFT JOIN TGFPRO PRO ON IBX.CODPROD = PRO.CODPROD
 		WHERE (BXA.USUGER = %s OR %s IS NULL)
 		  AND IBX.APP = 'S'
+		  AND IBX.CODARM IN (%s)
+		  AND (IBX.ARMDES IS NULL OR IBX.ARMDES IN (%s))
 		  AND TRUNC(BXA.DATGER) BETWEEN TO_DATE('%s', 'DD/MM/YYYY') AND TO_DATE('%s', 'DD/MM/YYYY')
 
 		UNION ALL
@@ -267,11 +425,12 @@ func (c *Client) GetHistory(ctx context.Context, dtIni string, dtFim string, cod
 		       NULL
 		FROM AD_HISTENDAPP H
 		WHERE (H.CODUSU = %s OR %s IS NULL)
+		  AND H.CODARM IN (%s)
 		  AND TRUNC(H.DTHOPER) BETWEEN TO_DATE('%s', 'DD/MM/YYYY') AND TO_DATE('%s', 'DD/MM/YYYY')
 
 		ORDER BY 2 DESC, 16 ASC`, 
-		codUsuStr, codUsuStr, safeDtIni, safeDtFim, 
-		codUsuStr, codUsuStr, safeDtIni, safeDtFim)
+		codUsuStr, codUsuStr, armList, armList, safeDtIni, safeDtFim, 
+		codUsuStr, codUsuStr, armList, safeDtIni, safeDtFim)
 
 	rows, err := c.executeQuery(ctx, sql)
 	if err != nil {
@@ -0,0 +1,255 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	putawayDefaultLimit = 20
+	putawayMaxLimit     = 200
+)
+
+// ListEmptyAddresses lista os endereços sem produto ou com saldo zerado, na ordem rua/prédio/apartamento
+func (c *Client) ListEmptyAddresses(ctx context.Context, input EmptyAddressInput) ([]EmptyAddress, error) {
+	limit := clampLimit(input.Limit, putawayDefaultLimit, putawayMaxLimit)
+
+	where := []string{
+		fmt.Sprintf("ENDE.CODARM = %d", input.CodArm),
+		"(NVL(ENDE.CODPROD, 0) = 0 OR NVL(ENDE.QTDPRO, 0) <= 0)",
+	}
+	if rua := strings.TrimSpace(input.CodRua); rua != "" {
+		where = append(where, fmt.Sprintf("ENDE.CODRUA = '%s'", sanitizeStringForSql(rua)))
+	}
+	if input.CodPrd > 0 {
+		where = append(where, fmt.Sprintf("ENDE.CODPRD = %d", input.CodPrd))
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT ENDE.SEQEND, ENDE.CODRUA, ENDE.CODPRD, ENDE.CODAPT, ENDE.ENDPIC, ENDE.CODPROD
+			  FROM AD_CADEND ENDE
+			 WHERE %s
+			 ORDER BY ENDE.CODRUA, ENDE.CODPRD, ENDE.CODAPT, ENDE.SEQEND
+		) WHERE ROWNUM <= %d`, strings.Join(where, "\n			   AND "), limit)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]EmptyAddress, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, EmptyAddress{
+			SeqEnd:  int(safeFloat64(row[0])),
+			CodRua:  safeString(row[1]),
+			CodPrd:  int(safeFloat64(row[2])),
+			CodApt:  safeString(row[3]),
+			EndPic:  safeString(row[4]),
+			CodProd: int(safeFloat64(row[5])),
+		})
+	}
+	return results, nil
+}
+
+// GetPutawaySuggestions ranqueia onde armazenar um produto: primeiro endereços com o mesmo
+// produto e validade (consolidação), depois os vazios mais próximos da referência.
+// Endereços de picking ficam de fora: são abastecidos pela reposição.
+func (c *Client) GetPutawaySuggestions(ctx context.Context, input PutawayInput) (*PutawaySuggestion, error) {
+	limit := clampLimit(input.Limit, putawayDefaultLimit, putawayMaxLimit)
+
+	sug := &PutawaySuggestion{
+		CodArm:     input.CodArm,
+		CodProd:    input.CodProd,
+		DatVal:     strings.TrimSpace(input.DatVal),
+		Quantidade: input.Quantidade,
+		Candidatos: []PutawayCandidate{},
+	}
+
+	// Referência de distância: o endereço de origem ou, sem ele, o primeiro endereço do produto
+	var refRua string
+	var refPrd int
+
+	if input.Sequencia > 0 {
+		origem, err := c.GetItemDetails(ctx, input.CodArm, strconv.Itoa(input.Sequencia))
+		if err != nil {
+			return nil, err
+		}
+		sug.CodProd = origem.CodProd
+		sug.DescrProd = origem.DescrProd
+		sug.DatVal = normalizeDate(origem.DatVal)
+		if sug.Quantidade <= 0 {
+			sug.Quantidade = origem.QtdPro
+		}
+		refRua, refPrd = origem.CodRua, origem.CodPrd
+	}
+	if sug.CodProd <= 0 {
+		return nil, ErrPutawaySemProduto
+	}
+
+	where := []string{
+		fmt.Sprintf("ENDE.CODARM = %d", input.CodArm),
+		"NVL(ENDE.ENDPIC, 'N') <> 'S'",
+		fmt.Sprintf("ENDE.SEQEND <> %d", input.Sequencia),
+	}
+	if rua := strings.TrimSpace(input.CodRua); rua != "" {
+		where = append(where, fmt.Sprintf("ENDE.CODRUA = '%s'", sanitizeStringForSql(rua)))
+	}
+
+	consolidar := "0 = 1"
+	if sug.DatVal != "" {
+		consolidar = fmt.Sprintf("(ENDE.CODPROD = %d AND NVL(ENDE.QTDPRO, 0) > 0 AND TO_CHAR(ENDE.DATVAL, 'DD/MM/YYYY') = '%s')",
+			sug.CodProd, sanitizeStringForSql(sug.DatVal))
+	}
+	where = append(where, fmt.Sprintf("(NVL(ENDE.CODPROD, 0) = 0 OR NVL(ENDE.QTDPRO, 0) <= 0 OR %s)", consolidar))
+
+	sql := fmt.Sprintf(`
+		SELECT ENDE.SEQEND, 
+		       ENDE.CODRUA, 
+		       ENDE.CODPRD, 
+		       ENDE.CODAPT, 
+		       NVL(ENDE.QTDPRO, 0) AS QTDPRO, 
+		       TO_CHAR(ENDE.DATVAL, 'DD/MM/YYYY') AS DATVAL, 
+		       CASE WHEN %s THEN 'CONSOLIDAR' ELSE 'VAZIO' END AS TIPO,
+		       (SELECT MAX(P.DESCRPROD) FROM TGFPRO P WHERE P.CODPROD = %d) AS DESCRPROD,
+		       (SELECT MIN(R.CODRUA || '|' || R.CODPRD) 
+		          FROM AD_CADEND R 
+		         WHERE R.CODARM = ENDE.CODARM AND R.CODPROD = %d AND NVL(R.QTDPRO, 0) > 0) AS REF_PRODUTO
+		  FROM AD_CADEND ENDE
+		 WHERE %s`, consolidar, sug.CodProd, sug.CodProd, strings.Join(where, "\n		   AND "))
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > 0 {
+		if sug.DescrProd == "" {
+			sug.DescrProd = safeString(rows[0][7])
+		}
+		if refRua == "" {
+			if parts := strings.SplitN(safeString(rows[0][8]), "|", 2); len(parts) == 2 {
+				refRua = parts[0]
+				refPrd, _ = strconv.Atoi(parts[1])
+			}
+		}
+	}
+	if refRua != "" {
+		sug.Referencia = fmt.Sprintf("%s-%d", refRua, refPrd)
+	}
+
+	candidatos := make([]PutawayCandidate, 0, len(rows))
+	for _, row := range rows {
+		cand := PutawayCandidate{
+			SeqEnd:   int(safeFloat64(row[0])),
+			CodRua:   safeString(row[1]),
+			CodPrd:   int(safeFloat64(row[2])),
+			CodApt:   safeString(row[3]),
+			QtdAtual: safeFloat64(row[4]),
+			Tipo:     safeString(row[6]),
+		}
+		if cand.Tipo == "CONSOLIDAR" {
+			cand.DatVal = safeString(row[5])
+		}
+		cand.Distancia = addressDistance(refRua, refPrd, cand.CodRua, cand.CodPrd)
+		candidatos = append(candidatos, cand)
+	}
+
+	// Consolidação antes de vazio; depois distância, apartamento mais baixo e SEQEND
+	sort.SliceStable(candidatos, func(i, j int) bool {
+		a, b := candidatos[i], candidatos[j]
+		if a.Tipo != b.Tipo {
+			return a.Tipo == "CONSOLIDAR"
+		}
+		if a.Distancia != b.Distancia {
+			return a.Distancia < b.Distancia
+		}
+		if a.CodApt != b.CodApt {
+			return a.CodApt < b.CodApt
+		}
+		return a.SeqEnd < b.SeqEnd
+	})
+
+	if len(candidatos) > limit {
+		candidatos = candidatos[:limit]
+	}
+
+	for i := range candidatos {
+		candidatos[i].Rank = i + 1
+		if input.Sequencia > 0 {
+			candidatos[i].Transacao = &SuggestedTransaction{
+				Type: "transferencia",
+				Payload: map[string]any{
+					"origem": map[string]any{
+						"codarm":    input.CodArm,
+						"sequencia": input.Sequencia,
+						"codprod":   sug.CodProd,
+					},
+					"destino": map[string]any{
+						"armazemDestino":  input.CodArm,
+						"enderecoDestino": strconv.Itoa(candidatos[i].SeqEnd),
+						"quantidade":      sug.Quantidade,
+						"criarPick":       false,
+					},
+				},
+			}
+		}
+	}
+	sug.Candidatos = candidatos
+
+	slog.Debug("Sugestões de armazenagem geradas", "codArm", input.CodArm, "codProd", sug.CodProd, "count", len(candidatos))
+	return sug, nil
+}
+
+// addressDistance aproxima a distância física: trocar de rua pesa mais que andar entre prédios.
+// Ruas numéricas usam a diferença entre elas; as demais contam como uma troca de rua.
+func addressDistance(refRua string, refPrd int, rua string, prd int) int {
+	if refRua == "" {
+		return 0
+	}
+
+	ruaDist := 0
+	if rua != refRua {
+		ruaDist = 1
+		a, errA := strconv.Atoi(strings.TrimSpace(refRua))
+		b, errB := strconv.Atoi(strings.TrimSpace(rua))
+		if errA == nil && errB == nil {
+			ruaDist = absInt(a - b)
+		}
+	}
+	return ruaDist*1000 + absInt(prd-refPrd)
+}
+
+// normalizeDate converte a data crua do ERP para DD/MM/YYYY (vazio se não reconhecida)
+func normalizeDate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 10 && s[2] == '/' && s[5] == '/' {
+		return s[:10]
+	}
+	// DbExplorer devolve datas como ddMMyyyy HH:mm:ss
+	if len(s) >= 8 && onlyDigitsRegex.MatchString(s[:8]) {
+		return s[0:2] + "/" + s[2:4] + "/" + s[4:8]
+	}
+	return ""
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func clampLimit(limit, def, max int) int {
+	if limit <= 0 {
+		return def
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
@@ -0,0 +1,57 @@
+package sankhya
+
+// EmptyAddressInput filtra a busca de endereços vazios (rua/prédio opcionais)
+type EmptyAddressInput struct {
+	CodArm int    `json:"codArm"`
+	CodRua string `json:"codRua"`
+	CodPrd int    `json:"codPrd"`
+	Limit  int    `json:"limit"`
+}
+
+// EmptyAddress é um endereço sem produto ou com saldo zerado
+type EmptyAddress struct {
+	SeqEnd int    `json:"seqEnd"`
+	CodRua string `json:"codRua"`
+	CodPrd int    `json:"codPrd"`
+	CodApt string `json:"codApt"`
+	EndPic string `json:"endPic"`
+	// Último produto do endereço (saldo zerado mantém o CODPROD em AD_CADEND)
+	CodProd int `json:"codProd,omitempty"`
+}
+
+// PutawayInput descreve o que vai ser armazenado. Com Sequencia (endereço de origem),
+// produto, validade e quantidade vêm do próprio endereço e cada candidato traz a transferência pronta.
+type PutawayInput struct {
+	CodArm     int     `json:"codArm"`
+	Sequencia  int     `json:"sequencia"`
+	CodProd    int     `json:"codProd"`
+	DatVal     string  `json:"datVal"` // DD/MM/YYYY
+	Quantidade float64 `json:"quantidade"`
+	CodRua     string  `json:"codRua"`
+	Limit      int     `json:"limit"`
+}
+
+// PutawayCandidate é um endereço sugerido, do melhor para o pior
+type PutawayCandidate struct {
+	Rank      int                   `json:"rank"`
+	Tipo      string                `json:"tipo"` // CONSOLIDAR (mesmo produto e validade) ou VAZIO
+	SeqEnd    int                   `json:"seqEnd"`
+	CodRua    string                `json:"codRua"`
+	CodPrd    int                   `json:"codPrd"`
+	CodApt    string                `json:"codApt"`
+	QtdAtual  float64               `json:"qtdAtual"`
+	DatVal    string                `json:"datVal,omitempty"`
+	Distancia int                   `json:"distancia"`
+	Transacao *SuggestedTransaction `json:"transacao,omitempty"`
+}
+
+// PutawaySuggestion é a resposta da sugestão de armazenagem
+type PutawaySuggestion struct {
+	CodArm     int                `json:"codArm"`
+	CodProd    int                `json:"codProd"`
+	DescrProd  string             `json:"descrProd"`
+	DatVal     string             `json:"datVal"`
+	Quantidade float64            `json:"quantidade"`
+	Referencia string             `json:"referencia"` // Rua/prédio usados para medir a distância
+	Candidatos []PutawayCandidate `json:"candidatos"`
+}
@@ -0,0 +1,185 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+)
+
+// GetReplenishmentSuggestions compara os endereços de picking com o mínimo configurado
+// (AD_PICKMIN ou o padrão do .env) e distribui o pulmão do mesmo produto, validade mais
+// antiga primeiro, em transações de picking prontas para execução
+func (c *Client) GetReplenishmentSuggestions(ctx context.Context, codArm int, codProd int) (*ReplenishmentReport, error) {
+	report := &ReplenishmentReport{CodArm: codArm, Sugestoes: []ReplenishmentSuggestion{}}
+
+	filtroProd := ""
+	if codProd > 0 {
+		filtroProd = fmt.Sprintf("AND ENDE.CODPROD = %d", codProd)
+	}
+
+	// Sem mínimo em AD_PICKMIN e sem padrão no .env o endereço não é monitorado
+	sqlPicking := fmt.Sprintf(`
+		SELECT ENDE.SEQEND, 
+		       ENDE.CODPROD, 
+		       PRO.DESCRPROD, 
+		       PRO.MARCA, 
+		       ENDE.CODVOL, 
+		       NVL(ENDE.QTDPRO, 0) AS QTDPRO, 
+		       NVL(PMIN.QTDMIN, %[2]s) AS QTDMIN, 
+		       NVL(PMIN.QTDMAX, 0) AS QTDMAX
+		  FROM AD_CADEND ENDE 
+		  JOIN TGFPRO PRO ON PRO.CODPROD = ENDE.CODPROD
+		  LEFT JOIN AD_PICKMIN PMIN ON PMIN.CODARM = ENDE.CODARM AND PMIN.SEQEND = ENDE.SEQEND
+		 WHERE ENDE.CODARM = %[1]d 
+		   AND ENDE.ENDPIC = 'S' 
+		   AND NVL(ENDE.CODPROD, 0) > 0 
+		   %[3]s
+		   AND NVL(ENDE.QTDPRO, 0) < NVL(PMIN.QTDMIN, %[2]s)
+		 ORDER BY NVL(ENDE.QTDPRO, 0) / NULLIF(NVL(PMIN.QTDMIN, %[2]s), 0) ASC, ENDE.SEQEND`,
+		codArm, strconv.FormatFloat(c.cfg.ReposicaoMinimoPadrao, 'f', -1, 64), filtroProd)
+
+	rows, err := c.executeQuery(ctx, sqlPicking)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return report, nil
+	}
+
+	var produtos []int
+	vistos := make(map[int]bool)
+	for _, row := range rows {
+		p := int(safeFloat64(row[1]))
+		if !vistos[p] {
+			vistos[p] = true
+			produtos = append(produtos, p)
+		}
+	}
+
+	pulmoes, err := c.getBulkSources(ctx, codArm, produtos)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		sug := ReplenishmentSuggestion{
+			SeqEnd:    int(safeFloat64(row[0])),
+			CodProd:   int(safeFloat64(row[1])),
+			DescrProd: safeString(row[2]),
+			Marca:     safeString(row[3]),
+			CodVol:    safeString(row[4]),
+			QtdAtual:  safeFloat64(row[5]),
+			QtdMinima: safeFloat64(row[6]),
+			Origens:   []ReplenishmentSource{},
+		}
+		sug.QtdAlvo = c.replenishmentTarget(sug.QtdMinima, safeFloat64(row[7]))
+		sug.QtdFaltante = roundQty(sug.QtdAlvo - sug.QtdAtual)
+
+		// Pulmões são compartilhados entre faces do mesmo produto: o saldo usado é abatido
+		restante := sug.QtdFaltante
+		for _, src := range pulmoes[bulkKey(sug.CodProd, sug.CodVol)] {
+			if restante <= 0 {
+				break
+			}
+			if src.disponivel <= 0 {
+				continue
+			}
+
+			qtd := roundQty(math.Min(restante, src.disponivel))
+			src.disponivel = roundQty(src.disponivel - qtd)
+			restante = roundQty(restante - qtd)
+
+			sug.Origens = append(sug.Origens, ReplenishmentSource{
+				SeqEnd:        src.seqEnd,
+				DatVal:        src.datVal,
+				QtdDisponivel: src.qtdPro,
+				Quantidade:    qtd,
+				Transacao: SuggestedTransaction{
+					Type: "picking",
+					Payload: map[string]any{
+						"origem": map[string]any{
+							"codarm":    codArm,
+							"sequencia": src.seqEnd,
+						},
+						"destino": map[string]any{
+							"armazemDestino":  codArm,
+							"enderecoDestino": strconv.Itoa(sug.SeqEnd),
+							"quantidade":      qtd,
+						},
+					},
+				},
+			})
+			sug.QtdSugerida = roundQty(sug.QtdSugerida + qtd)
+		}
+
+		report.Sugestoes = append(report.Sugestoes, sug)
+	}
+
+	slog.Debug("Sugestões de reposição geradas", "codArm", codArm, "count", len(report.Sugestoes))
+	return report, nil
+}
+
+// replenishmentTarget define até quanto repor: QTDMAX do endereço, o alvo padrão do .env
+// ou, sem nenhum dos dois, o dobro do mínimo
+func (c *Client) replenishmentTarget(minimo float64, maximo float64) float64 {
+	if maximo > minimo {
+		return maximo
+	}
+	if c.cfg.ReposicaoAlvoPadrao > minimo {
+		return c.cfg.ReposicaoAlvoPadrao
+	}
+	return minimo * 2
+}
+
+// bulkSource é um endereço de pulmão com o saldo ainda não reservado pelas sugestões
+type bulkSource struct {
+	seqEnd     int
+	datVal     string
+	qtdPro     float64
+	disponivel float64
+}
+
+func bulkKey(codProd int, codVol string) string {
+	return fmt.Sprintf("%d|%s", codProd, codVol)
+}
+
+// getBulkSources busca os pulmões (ENDPIC <> 'S') dos produtos, validade mais antiga primeiro
+func (c *Client) getBulkSources(ctx context.Context, codArm int, produtos []int) (map[string][]*bulkSource, error) {
+	sql := fmt.Sprintf(`
+		SELECT ENDE.SEQEND, 
+		       ENDE.CODPROD, 
+		       ENDE.CODVOL, 
+		       TO_CHAR(ENDE.DATVAL, 'DD/MM/YYYY') AS DATVAL, 
+		       ENDE.QTDPRO
+		  FROM AD_CADEND ENDE 
+		 WHERE ENDE.CODARM = %d 
+		   AND NVL(ENDE.ENDPIC, 'N') <> 'S' 
+		   AND ENDE.CODPROD IN (%s) 
+		   AND NVL(ENDE.QTDPRO, 0) > 0
+		 ORDER BY ENDE.DATVAL ASC NULLS LAST, ENDE.QTDPRO ASC, ENDE.SEQEND`, codArm, joinInts(produtos))
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string][]*bulkSource)
+	for _, row := range rows {
+		key := bulkKey(int(safeFloat64(row[1])), safeString(row[2]))
+		qtd := safeFloat64(row[4])
+		sources[key] = append(sources[key], &bulkSource{
+			seqEnd:     int(safeFloat64(row[0])),
+			datVal:     safeString(row[3]),
+			qtdPro:     qtd,
+			disponivel: qtd,
+		})
+	}
+	return sources, nil
+}
+
+// roundQty evita resíduos de ponto flutuante (o ERP grava com 3 casas)
+func roundQty(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
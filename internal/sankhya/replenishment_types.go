@@ -0,0 +1,45 @@
+package sankhya
+
+// SuggestedTransaction tem o mesmo formato do body de /apiv1/execute-transaction,
+// para o app reenviar a sugestão aceita sem montar o payload
+type SuggestedTransaction struct {
+	Type    string         `json:"type"`
+	Payload map[string]any `json:"payload"`
+}
+
+// ReplenishmentInput filtra as sugestões de reposição (CodProd opcional)
+type ReplenishmentInput struct {
+	CodArm  int `json:"codArm"`
+	CodProd int `json:"codProd"`
+}
+
+// ReplenishmentSource é um endereço de pulmão escolhido para abastecer o picking
+type ReplenishmentSource struct {
+	SeqEnd        int                  `json:"seqEnd"`
+	DatVal        string               `json:"datVal"`
+	QtdDisponivel float64              `json:"qtdDisponivel"`
+	Quantidade    float64              `json:"quantidade"`
+	Transacao     SuggestedTransaction `json:"transacao"`
+}
+
+// ReplenishmentSuggestion é um endereço de picking abaixo do mínimo
+type ReplenishmentSuggestion struct {
+	SeqEnd      int     `json:"seqEnd"`
+	CodProd     int     `json:"codProd"`
+	DescrProd   string  `json:"descrProd"`
+	Marca       string  `json:"marca"`
+	CodVol      string  `json:"codVol"`
+	QtdAtual    float64 `json:"qtdAtual"`
+	QtdMinima   float64 `json:"qtdMinima"`
+	QtdAlvo     float64 `json:"qtdAlvo"`
+	QtdFaltante float64 `json:"qtdFaltante"`
+	// QtdSugerida < QtdFaltante quando o pulmão não tem saldo suficiente
+	QtdSugerida float64               `json:"qtdSugerida"`
+	Origens     []ReplenishmentSource `json:"origens"`
+}
+
+// ReplenishmentReport lista as reposições sugeridas de um armazém
+type ReplenishmentReport struct {
+	CodArm    int                       `json:"codArm"`
+	Sugestoes []ReplenishmentSuggestion `json:"sugestoes"`
+}
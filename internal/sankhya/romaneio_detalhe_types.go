@@ -18,6 +18,7 @@ type RomaneioItem struct {
 	Conferido     string  `json:"conferido"`
 	NumReg        int     `json:"num_reg"`
 	ListaBarras   string  `json:"lista_barras"`
+	QtdEmbarcada  float64 `json:"qtd_embarcada"` // Quantidade conferida (0 enquanto não conferido)
 }
 
 // RomaneioDetalheResponse estrutura a resposta com cabeçalho único e lista de itens
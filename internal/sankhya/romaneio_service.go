@@ -3,14 +3,15 @@ package sankhya
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 )
 
-// GetRomaneios executa a consulta de fechamentos de carga com peso total e status de conferência
-func (c *Client) GetRomaneios(ctx context.Context, dataFiltro string) ([]RomaneioResult, error) {
-	safeData := sanitizeStringForSql(dataFiltro)
-
-	sql := fmt.Sprintf(`
+// romaneioBaseSQL é a consulta de fechamentos de carga com peso total e status de conferência,
+// compartilhada pela lista do dia e pela busca. SITUACAO é derivada de AD_ZNTCONFCAB: conferido
+// (CONFERIDO = 'S'), em andamento (conferente definido ou algum item conferido) ou pendente.
+const romaneioBaseSQL = `
 		SELECT FEC.NUFECHAMENTO AS FECHAMENTO,
 		       TO_CHAR(FEC.DTFECHAMENTO, 'DD/MM/YYYY') AS DATA,
 		       PAR.NOMEPARC AS MOTORISTA,
@@ -20,7 +21,16 @@ func (c *Client) GetRomaneios(ctx context.Context, dataFiltro string) ([]Romanei
 		       VEI.AD_QTDPALLET AS PALETES,
 		       FCAB.CODUSU,
 		       USU.NOMEUSUCPLT AS NOMEUSU,
-		       FCAB.STATUS
+		       FCAB.STATUS,
+		       FCAB.NUUNICO,
+		       CASE
+		         WHEN NVL(FCAB.CONFERIDO, 'N') = 'S' THEN 'conferido'
+		         WHEN FCAB.CODUSU IS NOT NULL
+		           OR EXISTS (SELECT 1 FROM AD_ZNTITEMCONF ITC
+		                       WHERE ITC.NUUNICO = FCAB.NUUNICO AND ITC.CONFERIDO = 'S') THEN 'em_andamento'
+		         ELSE 'pendente'
+		       END AS SITUACAO,
+		       FEC.DTFECHAMENTO
 		  FROM AD_FECCAR FEC
 		  JOIN AD_FECMOT MOT ON FEC.NUFECHAMENTO = MOT.NUFECHAMENTO
 		  JOIN TGFPAR PAR ON MOT.CODPARC = PAR.CODPARC
@@ -35,10 +45,16 @@ func (c *Client) GetRomaneios(ctx context.Context, dataFiltro string) ([]Romanei
 		         GROUP BY NUFECHAMENTO
 		  ) COM ON FEC.NUFECHAMENTO = COM.NUFECHAMENTO
 		 WHERE MOT.TIPO = 'M'
-		   AND NVL(FEC.STATUS, 'A') <> 'A'
+		   AND NVL(FEC.STATUS, 'A') <> 'A'`
+
+// GetRomaneios executa a consulta de fechamentos de carga com peso total e status de conferência
+func (c *Client) GetRomaneios(ctx context.Context, dataFiltro string) ([]RomaneioResult, error) {
+	safeData := sanitizeStringForSql(dataFiltro)
+
+	sql := fmt.Sprintf(`%s
 		   AND TRUNC(FEC.DTFECHAMENTO) = TO_DATE('%s', 'DD/MM/YYYY')
 		   AND FCAB.CONFERIDO <> 'S'
-		 ORDER BY FEC.NUFECHAMENTO DESC`, safeData)
+		 ORDER BY FEC.NUFECHAMENTO DESC`, romaneioBaseSQL, safeData)
 
 	rows, err := c.executeQuery(ctx, sql)
 	if err != nil {
@@ -47,35 +63,127 @@ func (c *Client) GetRomaneios(ctx context.Context, dataFiltro string) ([]Romanei
 
 	var results []RomaneioResult
 	for _, row := range rows {
-		// Helpers para evitar Panic com Nulls
-		getInt := func(v any) int {
-			if f, ok := v.(float64); ok { return int(f) }
-			return 0
-		}
-		getString := func(v any) string {
-			if v == nil { return "" }
-			return fmt.Sprintf("%v", v)
+		results = append(results, mapRomaneioRow(row))
+	}
+
+	return results, nil
+}
+
+// mapRomaneioRow converte uma linha de romaneioBaseSQL (nulls viram zero/vazio)
+func mapRomaneioRow(row []any) RomaneioResult {
+	return RomaneioResult{
+		Fechamento:  int(safeFloat64(row[0])),
+		Data:        safeString(row[1]),
+		Motorista:   safeString(row[2]),
+		Peso:        safeFloat64(row[3]),
+		Placa:       safeString(row[4]),
+		Veiculo:     safeString(row[5]),
+		Paletes:     safeFloat64(row[6]),
+		CodUsuario:  int(safeFloat64(row[7])), // Mapeando CODUSU
+		NomeUsuario: safeString(row[8]),       // Mapeando NOMEUSU
+		Status:      safeString(row[9]),       // Mapeando STATUS
+		NuUnico:     int(safeFloat64(row[10])),
+		Situacao:    safeString(row[11]),
+	}
+}
+
+const (
+	romaneioSearchDefaultLimit = 50
+	romaneioSearchMaxLimit     = 200
+	romaneioSearchMaxDias      = 93
+)
+
+// SearchRomaneios busca romaneios por período e filtros, paginada por offset (mais recentes primeiro).
+// Diferente de GetRomaneios, inclui os já conferidos.
+func (c *Client) SearchRomaneios(ctx context.Context, q RomaneioSearchQuery) (*RomaneioSearchPage, error) {
+	dtIni, err := time.Parse("02/01/2006", q.DtIni)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dtIni '%s' (use DD/MM/AAAA)", ErrRomaneioFiltro, q.DtIni)
+	}
+	dtFim, err := time.Parse("02/01/2006", q.DtFim)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dtFim '%s' (use DD/MM/AAAA)", ErrRomaneioFiltro, q.DtFim)
+	}
+	if dtFim.Before(dtIni) {
+		return nil, fmt.Errorf("%w: dtFim anterior a dtIni", ErrRomaneioFiltro)
+	}
+	if dtFim.Sub(dtIni) > romaneioSearchMaxDias*24*time.Hour {
+		return nil, fmt.Errorf("%w: período maior que %d dias", ErrRomaneioFiltro, romaneioSearchMaxDias)
+	}
+
+	status := strings.ToLower(strings.TrimSpace(q.Status))
+	switch status {
+	case "", RomaneioPendente, RomaneioEmAndamento, RomaneioConferido:
+	default:
+		return nil, fmt.Errorf("%w: status '%s'", ErrRomaneioFiltro, q.Status)
+	}
+
+	limit := clampLimit(q.Limit, romaneioSearchDefaultLimit, romaneioSearchMaxLimit)
+	offset := q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	where := []string{fmt.Sprintf("R.DTFECHAMENTO >= TO_DATE('%s', 'DD/MM/YYYY') AND R.DTFECHAMENTO < TO_DATE('%s', 'DD/MM/YYYY') + 1",
+		dtIni.Format("02/01/2006"), dtFim.Format("02/01/2006"))}
+	if status != "" {
+		where = append(where, fmt.Sprintf("R.SITUACAO = '%s'", status))
+	}
+	if v := sanitizeStringForSql(strings.ToUpper(strings.TrimSpace(q.Motorista))); v != "" {
+		where = append(where, fmt.Sprintf("UPPER(R.MOTORISTA) LIKE '%%%s%%'", v))
+	}
+	// Placa com ou sem hífen
+	if v := sanitizeStringForSql(strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(q.Placa))); v != "" {
+		where = append(where, fmt.Sprintf("REPLACE(UPPER(R.PLACA), '-', '') LIKE '%%%s%%'", v))
+	}
+	if v := sanitizeStringForSql(strings.ToUpper(strings.TrimSpace(q.Veiculo))); v != "" {
+		where = append(where, fmt.Sprintf("UPPER(R.VEICULO) LIKE '%%%s%%'", v))
+	}
+	if q.CodUsu > 0 {
+		where = append(where, fmt.Sprintf("R.CODUSU = %d", q.CodUsu))
+	}
+
+	// FECHAMENTO desempata para a paginação ser estável entre as páginas
+	sql := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT R.*,
+			       ROW_NUMBER() OVER (ORDER BY R.DTFECHAMENTO DESC, R.FECHAMENTO DESC) AS RN,
+			       COUNT(*) OVER () AS TOTAL
+			  FROM (%s) R
+			 WHERE %s
+		)
+		WHERE RN BETWEEN %d AND %d
+		ORDER BY RN`, romaneioBaseSQL, strings.Join(where, "\n\t\t\t   AND "), offset+1, offset+limit)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &RomaneioSearchPage{
+		Items:  make([]RomaneioResult, 0, len(rows)),
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, row := range rows {
+		page.Items = append(page.Items, mapRomaneioRow(row))
+	}
+	// TOTAL é a última coluna (depois de RN). Página vazia além do fim não traz o total: conta à parte
+	if len(rows) > 0 {
+		page.Total = int(safeFloat64(rows[0][len(rows[0])-1]))
+	} else if offset > 0 {
+		countRows, err := c.executeQuery(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM (%s) R WHERE %s`, romaneioBaseSQL, strings.Join(where, " AND ")))
+		if err != nil {
+			return nil, err
 		}
-		getFloat := func(v any) float64 {
-			if f, ok := v.(float64); ok { return f }
-			return 0
+		if len(countRows) > 0 {
+			page.Total = int(safeFloat64(countRows[0][0]))
 		}
-
-		results = append(results, RomaneioResult{
-			Fechamento:  getInt(row[0]),
-			Data:        getString(row[1]),
-			Motorista:   getString(row[2]),
-			Peso:        getFloat(row[3]),
-			Placa:       getString(row[4]),
-			Veiculo:     getString(row[5]),
-			Paletes:     getFloat(row[6]),
-			CodUsuario:  getInt(row[7]),    // Mapeando CODUSU
-			NomeUsuario: getString(row[8]), // Mapeando NOMEUSU
-			Status:      getString(row[9]), // Mapeando STATUS
-		})
 	}
+	page.HasMore = offset+len(page.Items) < page.Total
 
-	return results, nil
+	slog.Debug("Página de romaneios retornada", "dtIni", q.DtIni, "dtFim", q.DtFim, "status", status, "count", len(page.Items), "total", page.Total)
+	return page, nil
 }
 
 // GetRomaneioDetalhes busca os itens do romaneio com arredondamento corrigido
@@ -127,7 +235,8 @@ SELECT
     (SELECT LISTAGG(CODBARRA, ', ') WITHIN GROUP (ORDER BY CODBARRA)
        FROM TGFVOA 
       WHERE CODPROD = CONF.CODPROD
-        AND CODBARRA IS NOT NULL) AS LISTA_BARRAS
+        AND CODBARRA IS NOT NULL) AS LISTA_BARRAS,
+    NVL(CONF.QTDEMBARCADA, 0) AS QTDEMBARCADA
 FROM AD_ZNTITEMCONF CONF
 JOIN AD_ZNTCONFCAB CAB ON CONF.NUUNICO = CAB.NUUNICO
 JOIN AD_FECCAR FEC ON CONF.NUFECHAMENTO = FEC.NUFECHAMENTO
@@ -151,7 +260,7 @@ ORDER BY CONF.NUMREG`, nuFec)
 	// Permite retornar lista vazia se a conferência ainda não foi populada pela trigger,
 	// mas mantemos o erro caso não ache nada e isso seja crítico (opcional: remover if abaixo se quiser vazio)
 	if len(rows) == 0 {
-		return nil, fmt.Errorf("nenhum registro de conferência encontrado para o fechamento %d", nuFec)
+		return nil, fmt.Errorf("%w: nenhum registro de conferência encontrado para o fechamento %d", ErrConfNaoEncontrada, nuFec)
 	}
 
 	// Helpers (Null Safety)
@@ -186,7 +295,7 @@ ORDER BY CONF.NUMREG`, nuFec)
 		Produtos:          []RomaneioItem{},
 	}
 
-	// Mapeia os itens (11-21)
+	// Mapeia os itens (11-22)
 	for _, row := range rows {
 		res.Produtos = append(res.Produtos, RomaneioItem{
 			Tipo:          getString(row[11]),
@@ -200,6 +309,7 @@ ORDER BY CONF.NUMREG`, nuFec)
 			Conferido:     getString(row[19]),
 			NumReg:        getInt(row[20]),
 			ListaBarras:   getString(row[21]),
+			QtdEmbarcada:  getFloat(row[22]),
 		})
 	}
 
@@ -215,8 +325,8 @@ func (c *Client) IniciarConferencia(ctx context.Context, nuUnico int, snkSession
 	// então montamos apenas o conteúdo interno.
 	requestBody := map[string]any{
 		"stpCall": map[string]any{
-			"actionID":    "172",
-			"procName":    "STP_INICIAR_CONF_ZNT",
+			"actionID":    c.cfg.ErpActions.IniciarConf.ActionID,
+			"procName":    c.cfg.ErpActions.IniciarConf.ProcName,
 			"rootEntity":  "AD_ZNTCONFCAB",
 			"refreshType": "SEL",
 			"params": map[string]any{
@@ -284,8 +394,8 @@ func (c *Client) ConferirItem(ctx context.Context, input ConferirItemInput, snkS
 	// Payload completo
 	requestBody := map[string]any{
 		"stpCall": map[string]any{
-			"actionID":    "171",
-			"procName":    "STP_CONFERIR_ITEM_ZNT",
+			"actionID":    c.cfg.ErpActions.ConferirItem.ActionID,
+			"procName":    c.cfg.ErpActions.ConferirItem.ProcName,
 			"rootEntity":  "AD_ZNTITEMCONF",
 			"refreshType": "SEL",
 			"params": map[string]any{
@@ -357,8 +467,8 @@ func (c *Client) FinalizarConferencia(ctx context.Context, input FinalizarConfer
 	// Payload completo
 	requestBody := map[string]any{
 		"stpCall": map[string]any{
-			"actionID":    "173",
-			"procName":    "STP_FINALIZAR_CONF_ZNT",
+			"actionID":    c.cfg.ErpActions.FinalizarConf.ActionID,
+			"procName":    c.cfg.ErpActions.FinalizarConf.ProcName,
 			"rootEntity":  "AD_ZNTCONFCAB",
 			"refreshType": "SEL",
 			"params": map[string]any{
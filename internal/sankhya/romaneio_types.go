@@ -17,6 +17,37 @@ type RomaneioResult struct {
 	CodUsuario  int     `json:"cod_usuario"`
 	NomeUsuario string  `json:"nome_usuario"`
 	Status      string  `json:"status"`
+	NuUnico     int     `json:"nu_unico"`
+	Situacao    string  `json:"situacao"` // pendente | em_andamento | conferido
+}
+
+// Situação da conferência usada no filtro da busca de romaneios
+const (
+	RomaneioPendente    = "pendente"     // Conferência não iniciada
+	RomaneioEmAndamento = "em_andamento" // Conferente definido ou algum item conferido
+	RomaneioConferido   = "conferido"    // AD_ZNTCONFCAB.CONFERIDO = 'S'
+)
+
+// RomaneioSearchQuery são os filtros da busca de romaneios (período obrigatório)
+type RomaneioSearchQuery struct {
+	DtIni     string `json:"dtIni"`     // DD/MM/YYYY
+	DtFim     string `json:"dtFim"`     // DD/MM/YYYY
+	Status    string `json:"status"`    // Vazio = todas as situações
+	Motorista string `json:"motorista"` // Parte do nome
+	Placa     string `json:"placa"`     // Parte da placa, com ou sem hífen
+	Veiculo   string `json:"veiculo"`   // Parte do número interno ou do modelo
+	CodUsu    int    `json:"codUsu"`    // Conferente
+	Limit     int    `json:"limit"`
+	Offset    int    `json:"offset"`
+}
+
+// RomaneioSearchPage é uma página da busca de romaneios com o total do filtro
+type RomaneioSearchPage struct {
+	Items   []RomaneioResult `json:"items"`
+	Total   int              `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+	HasMore bool             `json:"hasMore"`
 }
 
 type IniciarConferenciaInput struct {
@@ -31,6 +62,8 @@ type ConferirItemInput struct {
 }
 
 type FinalizarConferenciaInput struct {
-	NuUnico int    `json:"nu_unico"`
-	ObsFim  string `json:"obs_fim"` // Opcional
+	NuUnico       int    `json:"nu_unico"`
+	ObsFim        string `json:"obs_fim"`       // Opcional
+	Forcar        bool   `json:"forcar"`        // Supervisor (SUPCONF) finaliza mesmo com divergências
+	Justificativa string `json:"justificativa"` // Obrigatória quando forcar = true
 }
\ No newline at end of file
@@ -0,0 +1,200 @@
+package sankhya
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// GetProductStock agrega o AD_CADEND de um produto nos armazéns informados (escopo do usuário)
+func (c *Client) GetProductStock(ctx context.Context, codProd int, armazens []int) (*ProductStockOverview, error) {
+	overview, err := c.getProductHeader(ctx, codProd)
+	if err != nil {
+		return nil, err
+	}
+	if len(armazens) == 0 {
+		return overview, nil
+	}
+
+	// FATOR converte CODVOL para a unidade padrão do produto (NULL quando não há cadastro em TGFVOA)
+	sql := fmt.Sprintf(`
+		SELECT ENDE.CODARM, 
+		       ARM.DESARM, 
+		       ENDE.SEQEND, 
+		       ENDE.CODRUA, 
+		       ENDE.CODPRD, 
+		       ENDE.CODAPT, 
+		       ENDE.ENDPIC, 
+		       TO_CHAR(ENDE.DATVAL, 'DD/MM/YYYY') AS DATVAL, 
+		       ENDE.CODVOL, 
+		       NVL(ENDE.QTDPRO, 0) AS QTDPRO, 
+		       VOA.DERIVACAO,
+		       CASE WHEN ENDE.CODVOL = PRO.CODVOL THEN 1 
+		            WHEN NVL(VOA.QUANTIDADE, 0) = 0 THEN NULL 
+		            WHEN VOA.DIVIDEMULTIPLICA = 'D' THEN 1 / VOA.QUANTIDADE 
+		            ELSE VOA.QUANTIDADE END AS FATOR
+		  FROM AD_CADEND ENDE 
+		  JOIN TGFPRO PRO ON PRO.CODPROD = ENDE.CODPROD
+		  JOIN AD_CADARM ARM ON ARM.CODARM = ENDE.CODARM
+		  LEFT JOIN (
+		        SELECT CODVOL, 
+		               MAX(QUANTIDADE) AS QUANTIDADE, 
+		               MAX(DIVIDEMULTIPLICA) AS DIVIDEMULTIPLICA, 
+		               MAX(DESCRDANFE) AS DERIVACAO 
+		          FROM TGFVOA 
+		         WHERE CODPROD = %d 
+		         GROUP BY CODVOL
+		  ) VOA ON VOA.CODVOL = ENDE.CODVOL
+		 WHERE ENDE.CODPROD = %d 
+		   AND ENDE.CODARM IN (%s)
+		 ORDER BY ENDE.CODARM, ENDE.ENDPIC DESC, ENDE.DATVAL ASC, ENDE.SEQEND`, codProd, codProd, joinInts(armazens))
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	porUnidade := newStockTotals()
+	porArmazem := make(map[int]*StockWarehouseTotal)
+	totaisArmazem := make(map[int]*stockTotals)
+	porValidade := make(map[string]*StockExpiryTotal)
+	totaisValidade := make(map[string]*stockTotals)
+	var ordemArmazens []int
+
+	for _, row := range rows {
+		end := StockAddress{
+			CodArm:     int(safeFloat64(row[0])),
+			SeqEnd:     int(safeFloat64(row[2])),
+			CodRua:     safeString(row[3]),
+			CodPrd:     int(safeFloat64(row[4])),
+			CodApt:     safeString(row[5]),
+			EndPic:     safeString(row[6]),
+			DatVal:     safeString(row[7]),
+			CodVol:     safeString(row[8]),
+			Quantidade: safeFloat64(row[9]),
+			Derivacao:  safeString(row[10]),
+		}
+		convertido := row[11] != nil
+		if convertido {
+			end.QtdPadrao = end.Quantidade * safeFloat64(row[11])
+		} else {
+			overview.ConversaoCompleta = false
+		}
+		overview.PorEndereco = append(overview.PorEndereco, end)
+
+		arm, ok := porArmazem[end.CodArm]
+		if !ok {
+			arm = &StockWarehouseTotal{CodArm: end.CodArm, DesArm: safeString(row[1])}
+			porArmazem[end.CodArm] = arm
+			totaisArmazem[end.CodArm] = newStockTotals()
+			ordemArmazens = append(ordemArmazens, end.CodArm)
+		}
+		val, ok := porValidade[end.DatVal]
+		if !ok {
+			val = &StockExpiryTotal{DatVal: end.DatVal}
+			porValidade[end.DatVal] = val
+			totaisValidade[end.DatVal] = newStockTotals()
+		}
+
+		porUnidade.add(end)
+		totaisArmazem[end.CodArm].add(end)
+		totaisValidade[end.DatVal].add(end)
+		if convertido {
+			overview.QtdPadrao += end.QtdPadrao
+			arm.QtdPadrao += end.QtdPadrao
+			val.QtdPadrao += end.QtdPadrao
+		}
+	}
+
+	overview.PorUnidade = porUnidade.list()
+	for _, codArm := range ordemArmazens {
+		arm := porArmazem[codArm]
+		arm.Totais = totaisArmazem[codArm].list()
+		overview.PorArmazem = append(overview.PorArmazem, *arm)
+	}
+	for datVal, val := range porValidade {
+		val.Totais = totaisValidade[datVal].list()
+		overview.PorValidade = append(overview.PorValidade, *val)
+	}
+	sortByExpiry(overview.PorValidade)
+
+	slog.Debug("Visão de estoque do produto", "codProd", codProd, "enderecos", len(overview.PorEndereco), "armazens", len(overview.PorArmazem))
+	return overview, nil
+}
+
+// getProductHeader carrega o cabeçalho do produto (ErrItemNotFound se não existir em TGFPRO)
+func (c *Client) getProductHeader(ctx context.Context, codProd int) (*ProductStockOverview, error) {
+	sql := fmt.Sprintf(`SELECT CODPROD, DESCRPROD, MARCA, CODVOL FROM TGFPRO WHERE CODPROD = %d`, codProd)
+
+	rows, err := c.executeQuery(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	return &ProductStockOverview{
+		CodProd:           int(safeFloat64(rows[0][0])),
+		DescrProd:         safeString(rows[0][1]),
+		Marca:             safeString(rows[0][2]),
+		CodVolPadrao:      safeString(rows[0][3]),
+		ConversaoCompleta: true,
+		PorUnidade:        []StockTotal{},
+		PorArmazem:        []StockWarehouseTotal{},
+		PorValidade:       []StockExpiryTotal{},
+		PorEndereco:       []StockAddress{},
+	}, nil
+}
+
+// stockTotals acumula StockTotal por CODVOL preservando a ordem de aparição
+type stockTotals struct {
+	byVol map[string]*StockTotal
+	order []string
+}
+
+func newStockTotals() *stockTotals {
+	return &stockTotals{byVol: make(map[string]*StockTotal)}
+}
+
+func (t *stockTotals) add(end StockAddress) {
+	total, ok := t.byVol[end.CodVol]
+	if !ok {
+		total = &StockTotal{CodVol: end.CodVol}
+		t.byVol[end.CodVol] = total
+		t.order = append(t.order, end.CodVol)
+	}
+	total.Quantidade += end.Quantidade
+	total.Enderecos++
+	if end.EndPic == "S" {
+		total.Picking += end.Quantidade
+	} else {
+		total.Pulmao += end.Quantidade
+	}
+}
+
+func (t *stockTotals) list() []StockTotal {
+	list := make([]StockTotal, 0, len(t.order))
+	for _, codVol := range t.order {
+		list = append(list, *t.byVol[codVol])
+	}
+	return list
+}
+
+// sortByExpiry ordena da validade mais próxima para a mais distante; sem validade vai para o fim
+func sortByExpiry(list []StockExpiryTotal) {
+	parse := func(s string) (time.Time, bool) {
+		t, err := time.Parse("02/01/2006", s)
+		return t, err == nil
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		ti, okI := parse(list[i].DatVal)
+		tj, okJ := parse(list[j].DatVal)
+		if okI != okJ {
+			return okI
+		}
+		return ti.Before(tj)
+	})
+}
@@ -0,0 +1,63 @@
+package sankhya
+
+// ProductStockInput identifica o produto consultado
+type ProductStockInput struct {
+	CodProd int `json:"codProd"`
+}
+
+// StockTotal soma os saldos de uma unidade (CODVOL), separando picking e pulmão
+type StockTotal struct {
+	CodVol     string  `json:"codVol"`
+	Quantidade float64 `json:"quantidade"`
+	Picking    float64 `json:"picking"`
+	Pulmao     float64 `json:"pulmao"`
+	Enderecos  int     `json:"enderecos"`
+}
+
+// StockAddress é o saldo de um endereço do produto
+type StockAddress struct {
+	CodArm     int     `json:"codArm"`
+	SeqEnd     int     `json:"seqEnd"`
+	CodRua     string  `json:"codRua"`
+	CodPrd     int     `json:"codPrd"`
+	CodApt     string  `json:"codApt"`
+	EndPic     string  `json:"endPic"`
+	DatVal     string  `json:"datVal"`
+	CodVol     string  `json:"codVol"`
+	Derivacao  string  `json:"derivacao"`
+	Quantidade float64 `json:"quantidade"`
+	QtdPadrao  float64 `json:"qtdPadrao"` // Convertida para a unidade padrão (TGFPRO.CODVOL)
+}
+
+// StockWarehouseTotal agrupa os totais de um armazém
+type StockWarehouseTotal struct {
+	CodArm    int          `json:"codArm"`
+	DesArm    string       `json:"desArm"`
+	QtdPadrao float64      `json:"qtdPadrao"`
+	Totais    []StockTotal `json:"totais"`
+}
+
+// StockExpiryTotal agrupa os totais de uma data de validade
+type StockExpiryTotal struct {
+	DatVal    string       `json:"datVal"` // Vazio = sem validade
+	QtdPadrao float64      `json:"qtdPadrao"`
+	Totais    []StockTotal `json:"totais"`
+}
+
+// ProductStockOverview responde "onde está o produto X e quanto temos"
+type ProductStockOverview struct {
+	CodProd      int    `json:"codProd"`
+	DescrProd    string `json:"descrProd"`
+	Marca        string `json:"marca"`
+	CodVolPadrao string `json:"codVolPadrao"`
+
+	// QtdPadrao soma todas as unidades convertidas para CodVolPadrao. ConversaoCompleta é
+	// falso quando alguma unidade não tem fator em TGFVOA (e ficou fora dessa soma).
+	QtdPadrao         float64 `json:"qtdPadrao"`
+	ConversaoCompleta bool    `json:"conversaoCompleta"`
+
+	PorUnidade  []StockTotal          `json:"porUnidade"`
+	PorArmazem  []StockWarehouseTotal `json:"porArmazem"`
+	PorValidade []StockExpiryTotal    `json:"porValidade"`
+	PorEndereco []StockAddress        `json:"porEndereco"`
+}
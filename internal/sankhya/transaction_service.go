@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -114,9 +115,17 @@ func (c *Client) ExecuteTransaction(ctx context.Context, input TransactionInput,
 		return "", ErrPermissionDenied
 	}
 
+	if err := perms.checkWarehouses(transactionWarehouses(input)...); err != nil {
+		return "", err
+	}
+
+	if err := c.applyGS1Scan(ctx, input); err != nil {
+		return "", err
+	}
+
 	switch input.Type {
 	case "correcao":
-		return c.handleCorrecao(ctx, input, snkSessionId)
+		return c.handleCorrecao(ctx, input, snkSessionId, perms)
 	case "picking":
 		return c.handlePicking(ctx, input, snkSessionId, perms)
 	default:
@@ -124,8 +133,25 @@ func (c *Client) ExecuteTransaction(ctx context.Context, input TransactionInput,
 	}
 }
 
-// handleCorrecao trata a lógica específica de correção de estoque
-func (c *Client) handleCorrecao(ctx context.Context, input TransactionInput, snkSessionId string) (string, error) {
+// transactionWarehouses lista os armazéns tocados pela transação (origem e destino)
+func transactionWarehouses(input TransactionInput) []int {
+	if input.Type == "correcao" {
+		return []int{int(safeFloat64(input.Payload["codarm"]))}
+	}
+
+	var armazens []int
+	if origemMap, ok := input.Payload["origem"].(map[string]any); ok {
+		armazens = append(armazens, int(safeFloat64(origemMap["codarm"])))
+	}
+	if destMap, ok := input.Payload["destino"].(map[string]any); ok {
+		armazens = append(armazens, int(safeFloat64(destMap["armazemDestino"])))
+	}
+	return armazens
+}
+
+// handleCorrecao trata a lógica específica de correção de estoque.
+// Correções cuja diferença ultrapassa o limite configurado ficam pendentes de aprovação.
+func (c *Client) handleCorrecao(ctx context.Context, input TransactionInput, snkSessionId string, perms *UserPermissions) (string, error) {
 	slog.Info("Iniciando Correção de Estoque", "user", input.CodUsu)
 
 	payload := input.Payload
@@ -133,6 +159,52 @@ func (c *Client) handleCorrecao(ctx context.Context, input TransactionInput, snk
 	sequencia := int(safeFloat64(payload["sequencia"]))
 	newQuantity := safeFloat64(payload["newQuantity"])
 
+	item, err := c.getCorrecaoItem(ctx, codArm, sequencia)
+	if err != nil {
+		return "", err
+	}
+
+	// Quem aprova correções não precisa aguardar a própria aprovação
+	if !perms.AprCorre && c.exceedsCorrecaoLimit(item.QtdAnt, newQuantity) {
+		// Uma solicitação por endereço: a segunda ficaria desatualizada assim que a primeira fosse aprovada
+		pendente, err := c.findCorrecaoPendente(ctx, codArm, sequencia)
+		if err != nil {
+			return "", fmt.Errorf("falha ao verificar correções pendentes: %w", err)
+		}
+		if pendente > 0 {
+			return "", fmt.Errorf("%w (solicitação %d)", ErrCorrecaoJaPendente, pendente)
+		}
+
+		nuCorr, err := c.createCorrecaoPendente(ctx, item, newQuantity, input.CodUsu)
+		if err != nil {
+			return "", fmt.Errorf("falha ao registrar correção pendente: %w", err)
+		}
+		slog.Info("Correção acima do limite enviada para aprovação", "user", input.CodUsu, "nucorr", nuCorr, "qtdAnt", item.QtdAnt, "qtdNova", newQuantity)
+		return fmt.Sprintf("Correção acima do limite permitido. Solicitação %s enviada para aprovação do supervisor.", nuCorr), nil
+	}
+
+	if err := c.applyCorrecao(ctx, item, newQuantity, input.CodUsu, 0, snkSessionId); err != nil {
+		return "", err
+	}
+
+	return "Estoque corrigido com sucesso!", nil
+}
+
+// correcaoItem guarda o estado do endereço no momento da correção
+type correcaoItem struct {
+	CodArm    int
+	SeqEnd    int
+	CodProd   string
+	CodVol    string
+	DatEnt    string
+	DatVal    string
+	QtdAnt    float64
+	Marca     string
+	Derivacao string
+}
+
+// getCorrecaoItem busca os dados atuais do endereço a ser corrigido
+func (c *Client) getCorrecaoItem(ctx context.Context, codArm int, sequencia int) (*correcaoItem, error) {
 	sqlItem := fmt.Sprintf(`
 		SELECT 
 			DEND.CODPROD, 
@@ -148,58 +220,95 @@ func (c *Client) handleCorrecao(ctx context.Context, input TransactionInput, snk
 
 	rows, err := c.executeQuery(ctx, sqlItem)
 	if err != nil || len(rows) == 0 {
-		return "", fmt.Errorf("item não encontrado para correção")
+		return nil, fmt.Errorf("item não encontrado para correção")
 	}
 	row := rows[0]
 
-	codProd := safeString(row[0])
-	codVol := safeString(row[1])
-	datEnt := safeString(row[2])
-	datVal := safeString(row[3])
-	qtdAnt := safeFloat64(row[4])
-	marca := safeString(row[5])
-	deriv := safeString(row[6])
+	return &correcaoItem{
+		CodArm:    codArm,
+		SeqEnd:    sequencia,
+		CodProd:   safeString(row[0]),
+		CodVol:    safeString(row[1]),
+		DatEnt:    safeString(row[2]),
+		DatVal:    safeString(row[3]),
+		QtdAnt:    safeFloat64(row[4]),
+		Marca:     safeString(row[5]),
+		Derivacao: safeString(row[6]),
+	}, nil
+}
+
+// exceedsCorrecaoLimit verifica se a diferença ultrapassa o limite absoluto ou percentual
+func (c *Client) exceedsCorrecaoLimit(qtdAnt, qtdNova float64) bool {
+	diff := math.Abs(qtdNova - qtdAnt)
+	if diff == 0 {
+		return false
+	}
+
+	if c.cfg.CorrecaoLimiteAbs > 0 && diff > c.cfg.CorrecaoLimiteAbs {
+		return true
+	}
+
+	if c.cfg.CorrecaoLimitePct > 0 {
+		// Sem saldo anterior qualquer diferença é 100% de variação
+		if qtdAnt == 0 {
+			return true
+		}
+		if diff/math.Abs(qtdAnt)*100 > c.cfg.CorrecaoLimitePct {
+			return true
+		}
+	}
+
+	return false
+}
 
+// applyCorrecao executa o script de correção e grava o histórico (com o aprovador, se houver)
+func (c *Client) applyCorrecao(ctx context.Context, item *correcaoItem, newQuantity float64, codUsu int, codUsuAprov int, snkSessionId string) error {
+	action := c.cfg.ErpActions.Correcao
 	scriptBody := ExecuteScriptBody{}
-	scriptBody.RunScript.ActionID = "97"
+	scriptBody.RunScript.ActionID = action.ActionID
 	scriptBody.RunScript.RefreshType = "SEL"
 	scriptBody.RunScript.Params.Param = []ScriptParam{
-		{Type: "S", ParamName: "CODPROD", Value: codProd},
-		{Type: "S", ParamName: "CODVOL", Value: codVol},
+		{Type: "S", ParamName: "CODPROD", Value: item.CodProd},
+		{Type: "S", ParamName: "CODVOL", Value: item.CodVol},
 		{Type: "F", ParamName: "QTDPRO", Value: newQuantity},
-		{Type: "D", ParamName: "DATENT", Value: datEnt},
-		{Type: "D", ParamName: "DATVAL", Value: datVal},
+		{Type: "D", ParamName: "DATENT", Value: item.DatEnt},
+		{Type: "D", ParamName: "DATVAL", Value: item.DatVal},
 	}
 	scriptBody.RunScript.Rows.Row = []ScriptRow{{
 		Field: []ScriptField{
-			{FieldName: "CODARM", Value: strconv.Itoa(codArm)},
-			{FieldName: "SEQEND", Value: strconv.Itoa(sequencia)},
+			{FieldName: "CODARM", Value: strconv.Itoa(item.CodArm)},
+			{FieldName: "SEQEND", Value: strconv.Itoa(item.SeqEnd)},
 		},
 	}}
 	scriptBody.ClientEventList.ClientEvent = []map[string]string{{"$": "br.com.sankhya.actionbutton.clientconfirm"}}
 
-	slog.Debug("Executando Script de Correção", "actionID", "97")
-	_, err = c.ExecuteServiceWithCookie(ctx, "ActionButtonsSP.executeScript", scriptBody, snkSessionId)
+	slog.Debug("Executando Script de Correção", "actionID", action.ActionID)
+	_, err := c.ExecuteServiceWithCookie(ctx, "ActionButtonsSP.executeScript", scriptBody, snkSessionId)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	fields := []string{"CODARM", "SEQEND", "CODPROD", "CODVOL", "MARCA", "DERIV", "QUANT", "QATUAL", "CODUSU"}
+	values := map[string]string{
+		"0": strconv.Itoa(item.CodArm),
+		"1": strconv.Itoa(item.SeqEnd),
+		"2": item.CodProd,
+		"3": item.CodVol,
+		"4": item.Marca,
+		"5": item.Derivacao,
+		"6": fmt.Sprintf("%.0f", item.QtdAnt),
+		"7": fmt.Sprintf("%.0f", newQuantity),
+		"8": strconv.Itoa(codUsu),
+	}
+	if codUsuAprov > 0 {
+		fields = append(fields, "CODUSUAPR")
+		values["9"] = strconv.Itoa(codUsuAprov)
 	}
 
 	histBody := DatasetSaveBody{
 		EntityName: "AD_HISTENDAPP",
-		Fields:     []string{"CODARM", "SEQEND", "CODPROD", "CODVOL", "MARCA", "DERIV", "QUANT", "QATUAL", "CODUSU"},
-		Records: []DatasetRecord{{
-			Values: map[string]string{
-				"0": strconv.Itoa(codArm),
-				"1": strconv.Itoa(sequencia),
-				"2": codProd,
-				"3": codVol,
-				"4": marca,
-				"5": deriv,
-				"6": fmt.Sprintf("%.0f", qtdAnt),
-				"7": fmt.Sprintf("%.0f", newQuantity),
-				"8": strconv.Itoa(input.CodUsu),
-			},
-		}},
+		Fields:     fields,
+		Records:    []DatasetRecord{{Values: values}},
 	}
 
 	slog.Debug("Salvando Histórico de Correção", "table", "AD_HISTENDAPP")
@@ -209,7 +318,7 @@ func (c *Client) handleCorrecao(ctx context.Context, input TransactionInput, snk
 		slog.Error("Erro ao salvar histórico de correção", "error", err)
 	}
 
-	return "Estoque corrigido com sucesso!", nil
+	return nil
 }
 
 // getOriginData busca CODPROD e ENDPIC da origem
@@ -373,8 +482,8 @@ func (c *Client) handlePicking(ctx context.Context, input TransactionInput, snkS
 	}
 
 	stpBody := ExecuteSTPBody{}
-	stpBody.StpCall.ActionID = "20"
-	stpBody.StpCall.ProcName = "NIC_STP_BAIXA_END"
+	stpBody.StpCall.ActionID = c.cfg.ErpActions.Movimentacao.ActionID
+	stpBody.StpCall.ProcName = c.cfg.ErpActions.Movimentacao.ProcName
 	stpBody.StpCall.RootEntity = "AD_BXAEND"
 	stpBody.StpCall.Rows.Row = []ScriptRow{{
 		Field: []ScriptField{{FieldName: "SEQBAI", Value: seqBai}},
@@ -525,8 +634,8 @@ func (c *Client) handleMovimentacao(ctx context.Context, input TransactionInput,
 	}
 
 	stpBody := ExecuteSTPBody{}
-	stpBody.StpCall.ActionID = "20"
-	stpBody.StpCall.ProcName = "NIC_STP_BAIXA_END"
+	stpBody.StpCall.ActionID = c.cfg.ErpActions.Movimentacao.ActionID
+	stpBody.StpCall.ProcName = c.cfg.ErpActions.Movimentacao.ProcName
 	stpBody.StpCall.RootEntity = "AD_BXAEND"
 	stpBody.StpCall.Rows.Row = []ScriptRow{{
 		Field: []ScriptField{{FieldName: "SEQBAI", Value: seqBai}},
@@ -11,6 +11,21 @@ var (
 	ErrPermissionDenied      = errors.New("permissão negada para esta operação")
 	// NOVO ERRO:
 	ErrUserSessionExpired    = errors.New("sessão do usuário expirada no ERP")
+	ErrCorrecaoNaoEncontrada = errors.New("solicitação de correção não encontrada ou já processada")
+	ErrCorrecaoDesatualizada = errors.New("o endereço mudou desde a solicitação (saldo, produto ou unidade). Rejeite e solicite novamente")
+	ErrCorrecaoStatus        = errors.New("correção aplicada no estoque, mas o status da solicitação não foi atualizado")
+	ErrCorrecaoJaPendente    = errors.New("já existe uma correção pendente de aprovação para este endereço")
+	ErrHistoryCursorInvalido = errors.New("cursor de paginação inválido")
+	ErrHistoryFiltroInvalido = errors.New("filtro de histórico inválido")
+	ErrOperacaoNaoEncontrada = errors.New("operação não encontrada")
+	ErrSearchSortInvalido    = errors.New("ordenação inválida")
+	ErrPutawaySemProduto     = errors.New("informe o codProd ou um endereço de origem com produto")
+	ErrWarehouseNotAllowed   = errors.New("armazém fora do escopo de permissões do usuário (AD_PERMEND)")
+	ErrGS1Invalido           = errors.New("etiqueta GS1 inválida")
+	ErrBarcodeAmbiguo        = errors.New("código de barras cadastrado em mais de um produto/unidade: informe codProd e codVol")
+	ErrGS1ProdutoDivergente  = errors.New("o GTIN da etiqueta não pertence ao produto do endereço")
+	ErrGS1ValidadeDivergente = errors.New("a validade da etiqueta não confere com a validade do endereço")
+	ErrRomaneioFiltro        = errors.New("filtro de romaneios inválido")
 )
 
 // --- Structs de Login (Service Account & Mobile) ---
@@ -158,6 +173,9 @@ type UserPermissions struct {
 	Corre        bool   `json:"CORRE"`
 	BxaPick      bool   `json:"BXAPICK"`
 	CriaPick     bool   `json:"CRIAPICK"`
+	AprCorre     bool   `json:"APRCORRE"` // Aprova correções acima do limite
+	HistGer      bool   `json:"HISTGER"`  // Consulta histórico de outros usuários
+	SupConf      bool   `json:"SUPCONF"`  // Supervisor de conferência (finaliza com divergências)
 }
 
 type ItemDetail struct {
@@ -177,6 +195,12 @@ type ItemDetail struct {
 	Derivacao   string  `json:"derivacao"`
 }
 
+// ItemAddress identifica um endereço (chave da consulta em lote de detalhes)
+type ItemAddress struct {
+	CodArm int
+	SeqEnd int
+}
+
 type SearchItemResult struct {
 	SeqEnd      int     `json:"seqEnd"`
 	CodRua      string  `json:"codRua"`
@@ -192,6 +216,25 @@ type SearchItemResult struct {
 	Derivacao   string  `json:"derivacao"`
 }
 
+// SearchItemsQuery são os parâmetros da busca paginada de itens
+type SearchItemsQuery struct {
+	CodArm int
+	Filtro string
+	Limit  int
+	Offset int
+	Sort   string // endereco, validade, quantidade, descricao (vazio = relevância)
+	Dir    string // asc (padrão) ou desc
+}
+
+// SearchItemsPage é uma página da busca de itens com o total do filtro
+type SearchItemsPage struct {
+	Items   []SearchItemResult `json:"items"`
+	Total   int                `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+	HasMore bool               `json:"hasMore"`
+}
+
 type PickingLocation struct {
 	SeqEnd    int    `json:"seqEnd"`
 	DescrProd string `json:"descrProd"`